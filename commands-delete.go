@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"path"
+	"strconv"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+type deleteTarget struct {
+	Path     string
+	Item     *sdk.DriveItem
+	NumItems int
+}
+
+var deleteOpts struct {
+	DryRun       bool
+	Permanent    bool
+	ConfirmAbove int
+	Yes          bool
+}
+
+func deleteFlags(f *flag.FlagSet) {
+	f.BoolVar(&deleteOpts.DryRun, "dry-run", false, "only print what would be deleted")
+	f.BoolVar(&deleteOpts.Permanent, "permanent", false, "delete permanently instead of moving to the recycle bin")
+	f.IntVar(&deleteOpts.ConfirmAbove, "confirm-above", 10, "ask for confirmation if more items would be deleted")
+	f.BoolVar(&deleteOpts.Yes, "yes", false, "don't ask for confirmation")
+}
+
+func cmdDelete(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	renderer.initSpinner("Collecting items...")
+	targets, err := collectDeleteTargets(client, args)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not delete: " + err.Error())
+		return
+	}
+	numItems := 0
+	for _, target := range targets {
+		numItems += target.NumItems
+	}
+	if deleteOpts.DryRun {
+		for _, target := range targets {
+			print("Would delete " + describeDeleteTarget(target))
+		}
+		log(strconv.Itoa(numItems) + " item(s) would be deleted.")
+		return
+	}
+	if !deleteOpts.Yes && numItems > deleteOpts.ConfirmAbove {
+		for _, target := range targets {
+			log(describeDeleteTarget(target))
+		}
+		if !confirm("Delete " + strconv.Itoa(numItems) + " item(s)?") {
+			logError("Aborted.")
+			return
+		}
+	}
+	paths := make([]string, len(targets))
+	items := make([]*sdk.DriveItem, len(targets))
+	for i, target := range targets {
+		paths[i], items[i] = target.Path, target.Item
+	}
+	deleteItems(client, renderer, paths, items, deleteOpts.Permanent)
+	log("Deleted " + strconv.Itoa(numItems) + " item(s).")
+}
+
+// deleteItems deletes the items at paths, either permanently or by moving
+// them to the recycle bin. Recycled items are recorded in the local index, so
+// they can be listed and restored using the trash command.
+func deleteItems(client *sdk.Client, renderer *OutputRenderer, paths []string, items []*sdk.DriveItem, permanent bool) {
+	recorder := newDeletionRecorder(client)
+	for i, p := range paths {
+		renderer.initSpinner("Deleting " + p + "...")
+		var err error
+		if permanent {
+			err = client.DeletePermanently(p)
+		} else {
+			err = client.Delete(p)
+		}
+		renderer.stopSpinner()
+		if err != nil {
+			recorder.write()
+			logError("Could not delete " + p + ": " + err.Error())
+			return
+		}
+		if !permanent {
+			recorder.add(p, items[i])
+		}
+		logVerbose("Deleted " + p)
+	}
+	recorder.write()
+}
+
+// collectDeleteTargets resolves the given paths and counts the items which
+// would be deleted, including the contents of folders. The folder contents
+// are only counted if they matter for the confirmation or the dry run.
+func collectDeleteTargets(client *sdk.Client, patterns []string) ([]*deleteTarget, error) {
+	var res []*deleteTarget
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := resolveDeletePattern(client, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, errors.New(pattern + ": " + sdk.ErrNotFound.Error())
+		}
+		for _, target := range matches {
+			if target.Path == "/" {
+				return nil, errors.New("refusing to delete the root folder")
+			}
+			if seen[target.Path] {
+				continue
+			}
+			seen[target.Path] = true
+			if target.Item.Type == sdk.DriveItemTypeFolder && (deleteOpts.DryRun || !deleteOpts.Yes) {
+				tree, err := client.ScanRemoteTree(target.Path)
+				if err != nil {
+					return nil, err
+				}
+				target.NumItems += len(tree)
+			}
+			res = append(res, target)
+		}
+	}
+	return res, nil
+}
+
+// resolveDeletePattern returns the item at pattern if it exists, so names
+// containing wildcard characters can be deleted, too. Otherwise the
+// wildcards in pattern are expanded.
+func resolveDeletePattern(client *sdk.Client, pattern string) ([]*deleteTarget, error) {
+	p := path.Clean("/" + pattern)
+	item, err := client.Info(p)
+	if err == nil {
+		return []*deleteTarget{{Path: p, Item: item, NumItems: 1}}, nil
+	}
+	if err != sdk.ErrNotFound {
+		return nil, err
+	}
+	paths, err := client.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var res []*deleteTarget
+	for _, p := range paths {
+		item, err := client.Info(p)
+		if err == sdk.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, &deleteTarget{Path: p, Item: item, NumItems: 1})
+	}
+	return res, nil
+}
+
+func describeDeleteTarget(target *deleteTarget) string {
+	if target.Item.Type == sdk.DriveItemTypeFolder {
+		return target.Path + "/ (folder, " + strconv.Itoa(target.NumItems-1) + " item(s) inside)"
+	}
+	return target.Path
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestResolveDeletePattern(t *testing.T) {
+	client := startTestGraphServer(t, map[string]string{
+		"/me/drive/root:/logs/a[1].txt":  `{"name":"a[1].txt","file":{"mimeType":"text/plain"}}`,
+		"/me/drive/root:/logs/a1.txt":    `{"name":"a1.txt","file":{"mimeType":"text/plain"}}`,
+		"/me/drive/root:/logs:/children": `{"value":[{"name":"a[1].txt","file":{"mimeType":"text/plain"}},{"name":"a1.txt","file":{"mimeType":"text/plain"}}]}`,
+	})
+
+	// Existing names are taken literally
+	targets, err := resolveDeletePattern(client, "/logs/a[1].txt")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(targets))
+	checkTestString(t, "/logs/a[1].txt", targets[0].Path)
+
+	targets, err = resolveDeletePattern(client, "logs/a*.txt")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(targets))
+	checkTestString(t, "/logs/a1.txt", targets[1].Path)
+
+	targets, err = resolveDeletePattern(client, "/logs/b*.txt")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 0, len(targets))
+}
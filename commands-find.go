@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+type findResult struct {
+	Path     string    `json:"path"`
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+var findOpts struct {
+	Name      string
+	Regex     string
+	Type      string
+	MinSize   string
+	MaxSize   string
+	ModBefore string
+	ModAfter  string
+	Print0    bool
+	JSONLines bool
+	Delete    bool
+	Permanent bool
+	Yes       bool
+}
+
+func findFlags(f *flag.FlagSet) {
+	f.StringVar(&findOpts.Name, "name", "", "only items whose name matches this pattern (e.g. '*.tar.gz')")
+	f.StringVar(&findOpts.Regex, "regex", "", "only items whose name matches this regular expression")
+	f.StringVar(&findOpts.Type, "type", "", "only items of this type (file, folder)")
+	f.StringVar(&findOpts.MinSize, "min-size", "", "only items of at least this size (e.g. 500M, 1G)")
+	f.StringVar(&findOpts.MaxSize, "max-size", "", "only items of at most this size")
+	f.StringVar(&findOpts.ModBefore, "modified-before", "", "only items modified before this date or age (e.g. 2026-01-31, 30d)")
+	f.StringVar(&findOpts.ModAfter, "modified-after", "", "only items modified after this date or age")
+	f.BoolVar(&findOpts.Print0, "print0", false, "print paths separated by NUL characters")
+	f.BoolVar(&findOpts.JSONLines, "jsonl", false, "print items as JSON lines")
+	f.BoolVar(&findOpts.Delete, "delete", false, "delete matching files (or folders with --type=folder) after confirmation")
+	f.BoolVar(&findOpts.Permanent, "permanent", false, "delete permanently instead of moving to the recycle bin")
+	f.BoolVar(&findOpts.Yes, "yes", false, "don't ask for confirmation before deleting")
+}
+
+func cmdFind(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	numModes := 0
+	for _, set := range []bool{findOpts.Print0, findOpts.JSONLines, findOpts.Delete} {
+		if set {
+			numModes++
+		}
+	}
+	if numModes > 1 {
+		logError("Only one of --print0, --jsonl and --delete can be used")
+		return
+	}
+	// The size and modification time of a folder don't reflect its
+	// children, so only files are deleted unless folders are asked for
+	if findOpts.Delete && findOpts.Type == "" {
+		findOpts.Type = "file"
+	}
+	filter, err := buildItemFilter(time.Now())
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	renderer.initSpinner("Searching...")
+	paths, items, err := client.Find(args[0], filter)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not search: " + err.Error())
+		return
+	}
+	if findOpts.Delete {
+		deleteFoundItems(client, renderer, paths, items)
+		return
+	}
+	for i, p := range paths {
+		switch {
+		case findOpts.Print0:
+			os.Stdout.WriteString(p + "\x00")
+		case findOpts.JSONLines:
+			itemType := "file"
+			if items[i].Type == sdk.DriveItemTypeFolder {
+				itemType = "folder"
+			}
+			data, _ := json.Marshal(&findResult{
+				Path:     p,
+				ID:       items[i].ID,
+				Type:     itemType,
+				Size:     items[i].SizeBytes,
+				Modified: items[i].FileSystemInfo.LastModified,
+			})
+			print(string(data))
+		default:
+			print(p)
+		}
+	}
+}
+
+func buildItemFilter(now time.Time) (*sdk.ItemFilter, error) {
+	filter := &sdk.ItemFilter{
+		NameGlob: findOpts.Name,
+	}
+	if findOpts.Regex != "" {
+		re, err := regexp.Compile(findOpts.Regex)
+		if err != nil {
+			return nil, err
+		}
+		filter.NameRegexp = re
+	}
+	switch findOpts.Type {
+	case "":
+	case "file":
+		filter.Type = sdk.DriveItemTypeFile
+	case "folder":
+		filter.Type = sdk.DriveItemTypeFolder
+	default:
+		return nil, errors.New("invalid type: " + findOpts.Type)
+	}
+	if findOpts.MinSize != "" {
+		size, err := parseSize(findOpts.MinSize)
+		if err != nil {
+			return nil, err
+		}
+		filter.MinSize = size
+	}
+	if findOpts.MaxSize != "" {
+		size, err := parseSize(findOpts.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+		filter.MaxSize = &size
+	}
+	var err error
+	if findOpts.ModBefore != "" {
+		if filter.ModifiedBefore, err = parseTimeSpec(findOpts.ModBefore, now, -1); err != nil {
+			return nil, err
+		}
+	}
+	if findOpts.ModAfter != "" {
+		if filter.ModifiedAfter, err = parseTimeSpec(findOpts.ModAfter, now, -1); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+// deleteFoundItems deletes the items after asking for confirmation. Items in
+// folders which are deleted as well are skipped.
+func deleteFoundItems(client *sdk.Client, renderer *OutputRenderer, paths []string, items []*sdk.DriveItem) {
+	var targets []string
+	var targetItems []*sdk.DriveItem
+	for i, p := range paths {
+		if len(targets) > 0 && strings.HasPrefix(p, targets[len(targets)-1]+"/") {
+			continue
+		}
+		targets = append(targets, p)
+		targetItems = append(targetItems, items[i])
+	}
+	if len(targets) == 0 {
+		log("No matching items.")
+		return
+	}
+	if !findOpts.Yes {
+		for _, p := range targets {
+			log(p)
+		}
+		if !confirm("Delete " + strconv.Itoa(len(targets)) + " item(s)?") {
+			logError("Aborted.")
+			return
+		}
+	}
+	deleteItems(client, renderer, targets, targetItems, findOpts.Permanent)
+	log("Deleted " + strconv.Itoa(len(targets)) + " item(s).")
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"flag"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+var listOpts struct {
+	Long          bool
+	HumanReadable bool
+	Hashes        bool
+	Sort          string
+	Recursive     bool
+	All           bool
+	Tree          bool
+}
+
+func listFlags(f *flag.FlagSet) {
+	f.BoolVar(&listOpts.Long, "l", false, "long listing format (size, modification time, child count or MIME type)")
+	f.BoolVar(&listOpts.HumanReadable, "h", false, "print sizes in human readable format (e.g. 1.5G)")
+	f.BoolVar(&listOpts.Hashes, "hashes", false, "include file hashes in long listing format")
+	f.StringVar(&listOpts.Sort, "sort", "name", "sort by name, size (largest first) or date (newest first)")
+	f.BoolVar(&listOpts.Recursive, "R", false, "list sub folders recursively")
+	f.BoolVar(&listOpts.All, "a", false, "mark special folders, packages (e.g. OneNote notebooks) and deleted items")
+	f.BoolVar(&listOpts.Tree, "tree", false, "show items as a tree")
+}
+
+func cmdList(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	switch listOpts.Sort {
+	case "name", "size", "date":
+	default:
+		logError("Invalid sort order: " + listOpts.Sort)
+		return
+	}
+	dir := args[0]
+	renderer.initSpinner("Retrieving directory listing...")
+	list, err := listItems(client, dir)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not list: " + err.Error())
+		return
+	}
+	switch {
+	case listOpts.Tree:
+		print(path.Clean("/" + dir))
+		err = printTree(client, dir, list, "")
+	case listOpts.Recursive:
+		err = printListingRecursive(client, dir, list)
+	default:
+		printListing(list)
+	}
+	if err != nil {
+		logError("Could not list: " + err.Error())
+	}
+}
+
+// listItems returns the items in dir sorted according to --sort.
+func listItems(client *sdk.Client, dir string) ([]*sdk.DriveItem, error) {
+	res, err := client.List(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		switch listOpts.Sort {
+		case "size":
+			return res[i].SizeBytes > res[j].SizeBytes
+		case "date":
+			return res[i].FileSystemInfo.LastModified.After(res[j].FileSystemInfo.LastModified)
+		default:
+			return strings.ToLower(res[i].Name) < strings.ToLower(res[j].Name)
+		}
+	})
+	return res, nil
+}
+
+func printListing(items []*sdk.DriveItem) {
+	if !listOpts.Long {
+		for _, item := range items {
+			print(itemTypeChar(item) + " " + itemName(item))
+		}
+		return
+	}
+	var rows [][]string
+	for _, item := range items {
+		info := item.File.MimeType
+		if item.Type == sdk.DriveItemTypeFolder {
+			info = strconv.Itoa(item.Folder.ChildCount) + " item(s)"
+		}
+		row := []string{
+			itemTypeChar(item),
+			formatItemSize(item.SizeBytes),
+			item.FileSystemInfo.LastModified.Local().Format(time.DateTime),
+			info,
+		}
+		if listOpts.Hashes {
+			hash := "-"
+			if algorithm, value := item.File.Hashes.Strongest(); algorithm != "" {
+				hash = string(algorithm) + ":" + value
+			}
+			row = append(row, hash)
+		}
+		rows = append(rows, append(row, itemName(item)))
+	}
+	printTable(rows)
+}
+
+// printListingRecursive prints the listing of dir followed by the listings
+// of all sub folders, each preceded by the folder's path.
+func printListingRecursive(client *sdk.Client, dir string, items []*sdk.DriveItem) error {
+	print(path.Clean("/"+dir) + ":")
+	printListing(items)
+	for _, item := range items {
+		if item.Type != sdk.DriveItemTypeFolder {
+			continue
+		}
+		subDir := path.Join("/", dir, item.Name)
+		children, err := listItems(client, subDir)
+		if err != nil {
+			return err
+		}
+		print("")
+		if err := printListingRecursive(client, subDir, children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printTree(client *sdk.Client, dir string, items []*sdk.DriveItem, prefix string) error {
+	for i, item := range items {
+		branch, indent := "├── ", "│   "
+		if i == len(items)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := item.Name
+		if item.Type == sdk.DriveItemTypeFolder {
+			name += "/"
+		}
+		name += itemFacets(item)
+		if listOpts.Long {
+			name += "  (" + formatItemSize(item.SizeBytes) + ", " + item.FileSystemInfo.LastModified.Local().Format(time.DateTime) + ")"
+		}
+		print(prefix + branch + name)
+		if item.Type != sdk.DriveItemTypeFolder {
+			continue
+		}
+		subDir := path.Join("/", dir, item.Name)
+		children, err := listItems(client, subDir)
+		if err != nil {
+			return err
+		}
+		if err := printTree(client, subDir, children, prefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// itemName returns the name of the item, followed by its special facets if
+// -a is set.
+func itemName(item *sdk.DriveItem) string {
+	return item.Name + itemFacets(item)
+}
+
+func itemFacets(item *sdk.DriveItem) string {
+	if !listOpts.All {
+		return ""
+	}
+	var facets []string
+	if item.SpecialFolder != nil {
+		facets = append(facets, "special folder: "+item.SpecialFolder.Name)
+	}
+	if item.Package != nil {
+		facets = append(facets, "package: "+item.Package.Type)
+	}
+	if item.Deleted != nil {
+		facets = append(facets, "deleted")
+	}
+	if len(facets) == 0 {
+		return ""
+	}
+	return "  [" + strings.Join(facets, ", ") + "]"
+}
+
+func itemTypeChar(item *sdk.DriveItem) string {
+	if item.Type == sdk.DriveItemTypeFolder {
+		return "d"
+	}
+	return "f"
+}
+
+func formatItemSize(size int64) string {
+	if listOpts.HumanReadable {
+		return humanSize(size)
+	}
+	return strconv.FormatInt(size, 10)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+func startTestListServer(t *testing.T) *sdk.Client {
+	oldListOpts := listOpts
+	t.Cleanup(func() { listOpts = oldListOpts })
+	return startTestGraphServer(t, map[string]string{
+		"/me/drive/root:/backup:/children": `{"value":[` +
+			`{"name":"b.tar.gz","size":300,"file":{"mimeType":"application/gzip"},"fileSystemInfo":{"lastModifiedDateTime":"2026-10-01T10:00:00Z"}},` +
+			`{"name":"Notes","size":5,"package":{"type":"oneNote"},"fileSystemInfo":{"lastModifiedDateTime":"2026-10-03T10:00:00Z"}},` +
+			`{"name":"A.tar.gz","size":100,"file":{"mimeType":"application/gzip"},"fileSystemInfo":{"lastModifiedDateTime":"2026-10-02T10:00:00Z"}},` +
+			`{"name":"old","size":2048,"folder":{"childCount":2},"fileSystemInfo":{"lastModifiedDateTime":"2026-09-01T10:00:00Z"}}]}`,
+		"/me/drive/root:/backup/old:/children": `{"value":[` +
+			`{"name":"c.tar.gz","size":1024,"file":{"mimeType":"application/gzip"}},` +
+			`{"name":".hidden","size":1024,"file":{"mimeType":"text/plain"}}]}`,
+		"/me/drive/root:/backup/Notes:/children": `{"value":[]}`,
+	})
+}
+
+// captureStdout returns what fn writes to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	checkTestBool(t, true, err == nil)
+	oldStdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	os.Stdout = oldStdout
+	w.Close()
+	return <-done
+}
+
+func itemNames(items []*sdk.DriveItem) string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestListItemsSort(t *testing.T) {
+	client := startTestListServer(t)
+
+	listOpts.Sort = "name"
+	items, err := listItems(client, "backup")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "A.tar.gz,b.tar.gz,Notes,old", itemNames(items))
+
+	listOpts.Sort = "size"
+	items, err = listItems(client, "backup")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "old,b.tar.gz,A.tar.gz,Notes", itemNames(items))
+
+	listOpts.Sort = "date"
+	items, err = listItems(client, "backup")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "Notes,A.tar.gz,b.tar.gz,old", itemNames(items))
+
+	// Names starting with a dot are listed, too
+	listOpts.Sort = "name"
+	items, err = listItems(client, "backup/old")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, ".hidden,c.tar.gz", itemNames(items))
+}
+
+func TestPrintListingRecursive(t *testing.T) {
+	client := startTestListServer(t)
+	listOpts.Sort = "name"
+	items, err := listItems(client, "backup")
+	checkTestBool(t, true, err == nil)
+
+	out := captureStdout(t, func() {
+		checkTestBool(t, true, printListingRecursive(client, "backup", items) == nil)
+	})
+	checkTestString(t, "/backup:\n"+
+		"f A.tar.gz\nf b.tar.gz\nd Notes\nd old\n"+
+		"\n/backup/Notes:\n"+
+		"\n/backup/old:\n"+
+		"f .hidden\nf c.tar.gz\n", out)
+
+	listOpts.All = true
+	out = captureStdout(t, func() {
+		printListing(items)
+	})
+	checkTestString(t, "f A.tar.gz\nf b.tar.gz\nd Notes  [package: oneNote]\nd old\n", out)
+}
+
+func TestPrintTree(t *testing.T) {
+	client := startTestListServer(t)
+	listOpts.Sort = "size"
+	listOpts.Long = true
+	listOpts.HumanReadable = true
+	items, err := listItems(client, "backup")
+	checkTestBool(t, true, err == nil)
+
+	out := captureStdout(t, func() {
+		checkTestBool(t, true, printTree(client, "backup", items, "") == nil)
+	})
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	checkTestInt(t, 6, len(lines))
+	checkTestBool(t, true, strings.HasPrefix(lines[0], "├── old/  (2.0K, "))
+	checkTestBool(t, true, strings.HasPrefix(lines[1], "│   ├── c.tar.gz  (1.0K, "))
+	checkTestBool(t, true, strings.HasPrefix(lines[2], "│   └── .hidden  (1.0K, "))
+	checkTestBool(t, true, strings.HasPrefix(lines[3], "├── b.tar.gz  (300, "))
+	checkTestBool(t, true, strings.HasPrefix(lines[4], "├── A.tar.gz  (100, "))
+	checkTestBool(t, true, strings.HasPrefix(lines[5], "└── Notes/  (5, "))
+}
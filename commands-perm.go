@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+var permOpts struct {
+	JSON    bool
+	Role    string
+	Message string
+	NoEmail bool
+}
+
+func permFlags(f *flag.FlagSet) {
+	f.BoolVar(&permOpts.JSON, "json", false, "print permissions as JSON")
+	f.StringVar(&permOpts.Role, "role", string(sdk.PermissionRoleRead), "role of invited recipients (read, write)")
+	f.StringVar(&permOpts.Message, "message", "", "message included in the invitation email")
+	f.BoolVar(&permOpts.NoEmail, "no-email", false, "don't send an invitation email")
+}
+
+func cmdPerm(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	switch {
+	case args[0] == "ls" && len(args) == 2:
+		cmdPermList(client, renderer, args[1])
+	case args[0] == "invite" && len(args) >= 3:
+		cmdPermInvite(client, renderer, args[1], args[2:])
+	case args[0] == "rm" && len(args) == 3:
+		cmdPermRemove(client, renderer, args[1], args[2])
+	default:
+		printHelp()
+	}
+}
+
+func cmdPermList(client *sdk.Client, renderer *OutputRenderer, remotePath string) {
+	renderer.initSpinner("Retrieving permissions...")
+	permissions, err := client.Permissions(remotePath)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not get permissions: " + err.Error())
+		return
+	}
+	printPermissions(permissions)
+}
+
+func cmdPermInvite(client *sdk.Client, renderer *OutputRenderer, remotePath string, emails []string) {
+	role := sdk.PermissionRole(permOpts.Role)
+	if role != sdk.PermissionRoleRead && role != sdk.PermissionRoleWrite {
+		logError("Invalid role: " + permOpts.Role)
+		return
+	}
+	renderer.initSpinner("Inviting...")
+	permissions, err := client.Invite(remotePath, emails, role, permOpts.Message, !permOpts.NoEmail)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not invite: " + err.Error())
+		return
+	}
+	printPermissions(permissions)
+}
+
+func cmdPermRemove(client *sdk.Client, renderer *OutputRenderer, remotePath, permissionID string) {
+	renderer.initSpinner("Removing permission...")
+	err := client.DeletePermission(remotePath, permissionID)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not remove permission: " + err.Error())
+		return
+	}
+	log("Permission removed.")
+}
+
+func printPermissions(permissions []*sdk.Permission) {
+	if permOpts.JSON {
+		data, err := json.MarshalIndent(permissions, "", "  ")
+		if err != nil {
+			logError("Could not encode permissions: " + err.Error())
+			return
+		}
+		os.Stdout.Write(append(data, '\n'))
+		return
+	}
+	rows := [][]string{{"ID", "ROLES", "GRANTED TO", "INHERITED"}}
+	for _, permission := range permissions {
+		inherited := "no"
+		if permission.InheritedFrom != nil {
+			inherited = "yes"
+		}
+		rows = append(rows, []string{
+			permission.ID,
+			strings.Join(permission.Roles, ","),
+			permission.Grantee(),
+			inherited,
+		})
+	}
+	printTable(rows)
+}
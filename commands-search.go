@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+var searchOpts struct {
+	In   string
+	Type string
+}
+
+func searchFlags(f *flag.FlagSet) {
+	f.StringVar(&searchOpts.In, "in", "", "only search in this folder")
+	f.StringVar(&searchOpts.Type, "type", "", "only show items of this type (file, folder)")
+}
+
+func cmdSearch(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	var itemType sdk.DriveItemType
+	switch searchOpts.Type {
+	case "":
+	case "file":
+		itemType = sdk.DriveItemTypeFile
+	case "folder":
+		itemType = sdk.DriveItemTypeFolder
+	default:
+		logError("Invalid type: " + searchOpts.Type)
+		return
+	}
+	renderer.initSpinner("Searching...")
+	items, err := client.Search(strings.Join(args, " "), searchOpts.In)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not search: " + err.Error())
+		return
+	}
+	var rows [][]string
+	for _, item := range items {
+		if itemType != 0 && item.Type != itemType {
+			continue
+		}
+		t := "f"
+		if item.Type == sdk.DriveItemTypeFolder {
+			t = "d"
+		}
+		p, err := client.ItemPath(item)
+		if err != nil {
+			logVerbose("Could not determine path of " + item.Name + ": " + err.Error())
+			p = item.Name + " (path unknown)"
+		}
+		rows = append(rows, []string{
+			t,
+			strconv.FormatInt(item.SizeBytes, 10),
+			item.FileSystemInfo.LastModified.Local().Format(time.DateTime),
+			p,
+		})
+	}
+	printTable(rows)
+}
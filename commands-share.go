@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+var shareOpts struct {
+	Type     string
+	Scope    string
+	Expires  string
+	Password string
+}
+
+func shareFlags(f *flag.FlagSet) {
+	f.StringVar(&shareOpts.Type, "type", string(sdk.LinkTypeView), "link type (view, edit, embed)")
+	f.StringVar(&shareOpts.Scope, "scope", "", "link scope (anonymous, organization; default depends on account)")
+	f.StringVar(&shareOpts.Expires, "expires", "", "expiry as duration (e.g. 7d, 12h) or date (e.g. 2026-12-31)")
+	f.StringVar(&shareOpts.Password, "password", "", "password for the link (personal accounts only)")
+}
+
+func cmdShare(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	switch {
+	case args[0] == "ls" && len(args) == 2:
+		cmdShareList(client, renderer, args[1])
+	case args[0] == "revoke" && len(args) == 3:
+		cmdShareRevoke(client, renderer, args[1], args[2])
+	case len(args) == 1:
+		cmdShareCreate(client, renderer, args[0])
+	default:
+		printHelp()
+	}
+}
+
+func cmdShareCreate(client *sdk.Client, renderer *OutputRenderer, remotePath string) {
+	linkType := sdk.LinkType(shareOpts.Type)
+	switch linkType {
+	case sdk.LinkTypeView, sdk.LinkTypeEdit, sdk.LinkTypeEmbed:
+	default:
+		logError("Invalid link type: " + shareOpts.Type)
+		return
+	}
+	scope := sdk.LinkScope(shareOpts.Scope)
+	switch scope {
+	case "", sdk.LinkScopeAnonymous, sdk.LinkScopeOrganization:
+	default:
+		logError("Invalid link scope: " + shareOpts.Scope)
+		return
+	}
+	var expiry time.Time
+	if shareOpts.Expires != "" {
+		var err error
+		expiry, err = parseTimeSpec(shareOpts.Expires, time.Now(), 1)
+		if err != nil {
+			logError("Invalid expiry: " + err.Error())
+			return
+		}
+	}
+	renderer.initSpinner("Creating link...")
+	permission, err := client.CreateLink(remotePath, linkType, scope, expiry, shareOpts.Password)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not create link: " + err.Error())
+		return
+	}
+	print(permission.Link.WebURL)
+}
+
+func cmdShareList(client *sdk.Client, renderer *OutputRenderer, remotePath string) {
+	renderer.initSpinner("Retrieving links...")
+	permissions, err := client.Permissions(remotePath)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not get links: " + err.Error())
+		return
+	}
+	rows := [][]string{{"ID", "TYPE", "SCOPE", "EXPIRES", "PASSWORD", "URL"}}
+	for _, permission := range permissions {
+		if permission.Link == nil {
+			continue
+		}
+		expires := "never"
+		if !permission.Expiry.IsZero() {
+			expires = permission.Expiry.Local().Format(time.DateTime)
+		}
+		rows = append(rows, []string{
+			permission.ID,
+			string(permission.Link.Type),
+			string(permission.Link.Scope),
+			expires,
+			strconv.FormatBool(permission.HasPassword),
+			permission.Link.WebURL,
+		})
+	}
+	printTable(rows)
+}
+
+func cmdShareRevoke(client *sdk.Client, renderer *OutputRenderer, remotePath, permissionID string) {
+	renderer.initSpinner("Revoking link...")
+	err := client.DeletePermission(remotePath, permissionID)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not revoke link: " + err.Error())
+		return
+	}
+	log("Link revoked.")
+}
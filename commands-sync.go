@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+var syncOpts struct {
+	Delete       bool
+	DryRun       bool
+	UseIndex     bool
+	TwoWay       bool
+	Conflict     string
+	ConfirmAbove int
+	Yes          bool
+}
+
+func syncFlags(f *flag.FlagSet) {
+	f.BoolVar(&syncOpts.Delete, "delete", false, "delete remote items not existing locally")
+	f.BoolVar(&syncOpts.DryRun, "dry-run", false, "print planned actions without executing them")
+	f.BoolVar(&syncOpts.UseIndex, "index", false, "use local index updated via delta query instead of listing remote tree")
+	f.BoolVar(&syncOpts.TwoWay, "two-way", false, "sync changes in both directions")
+	f.StringVar(&syncOpts.Conflict, "conflict", string(sdk.ConflictPolicyKeepBoth), "conflict policy for two-way sync (keep-both, newest-wins, local-wins, remote-wins)")
+	f.IntVar(&syncOpts.ConfirmAbove, "confirm-above", 10, "ask for confirmation if a two-way sync would delete more local items")
+	f.BoolVar(&syncOpts.Yes, "yes", false, "don't ask for confirmation")
+}
+
+var changesOpts struct {
+	Reset bool
+}
+
+func changesFlags(f *flag.FlagSet) {
+	f.BoolVar(&changesOpts.Reset, "reset", false, "rebuild local index from scratch")
+}
+
+func indexFilePath(client *sdk.Client) string {
+	configPath := client.Config.ConfigFilePath
+	return strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".index.json"
+}
+
+func openUpdatedStateDB(client *sdk.Client, renderer *OutputRenderer, reset bool) (*sdk.StateDB, []*sdk.StateChange) {
+	db, err := sdk.OpenStateDB(indexFilePath(client))
+	if err != nil {
+		logError("Could not open local index: " + err.Error())
+		return nil, nil
+	}
+	if reset {
+		db.Reset()
+	}
+	renderer.initSpinner("Fetching remote changes...")
+	changes, err := client.UpdateStateDB(db)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not fetch remote changes: " + err.Error())
+		return nil, nil
+	}
+	if err := db.Write(); err != nil {
+		logError("Could not write local index: " + err.Error())
+		return nil, nil
+	}
+	return db, changes
+}
+
+func cmdChanges(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	initial := false
+	if db, err := sdk.OpenStateDB(indexFilePath(client)); err == nil {
+		initial = db.DeltaLink == "" || changesOpts.Reset
+	}
+	db, changes := openUpdatedStateDB(client, renderer, changesOpts.Reset)
+	if initial {
+		log("Local index initialized with " + strconv.Itoa(len(db.Items)) + " item(s).")
+		return
+	}
+	for _, change := range changes {
+		changeType := "+"
+		switch change.Type {
+		case sdk.StateChangeModified:
+			changeType = "~"
+		case sdk.StateChangeDeleted:
+			changeType = "-"
+		}
+		print(changeType + " " + change.Path)
+	}
+}
+
+func cmdSync(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	localDir := filepath.Clean(args[0])
+	remoteDir := args[1]
+	renderer.initSpinner("Comparing local and remote files...")
+	local, err := client.ScanLocalTree(localDir)
+	if err != nil {
+		renderer.stopSpinner()
+		logError("Could not read local directory: " + err.Error())
+		return
+	}
+	var remote map[string]*sdk.DriveItem
+	if syncOpts.UseIndex {
+		renderer.stopSpinner()
+		db, _ := openUpdatedStateDB(client, renderer, false)
+		renderer.initSpinner("Comparing local and remote files...")
+		remote = db.Tree(remoteDir)
+	} else {
+		remote, err = client.ScanRemoteTree(remoteDir)
+		if err != nil {
+			renderer.stopSpinner()
+			logError("Could not read remote directory: " + err.Error())
+			return
+		}
+	}
+	opts := sdk.SyncOptions{
+		Delete:         syncOpts.Delete,
+		ConflictPolicy: sdk.ConflictPolicy(syncOpts.Conflict),
+	}
+	switch opts.ConflictPolicy {
+	case sdk.ConflictPolicyKeepBoth, sdk.ConflictPolicyNewestWins, sdk.ConflictPolicyLocalWins, sdk.ConflictPolicyRemoteWins:
+	default:
+		renderer.stopSpinner()
+		logError("Invalid conflict policy: " + syncOpts.Conflict)
+		return
+	}
+	var state *sdk.SyncState
+	var actions []*sdk.SyncAction
+	if syncOpts.TwoWay {
+		state, err = sdk.OpenSyncState(syncStateFilePath(client, localDir, remoteDir))
+		if err != nil {
+			renderer.stopSpinner()
+			logError("Could not read sync state: " + err.Error())
+			return
+		}
+		actions, err = sdk.PlanTwoWaySync(localDir, local, remote, state, opts)
+		if err == sdk.ErrSyncSideEmpty {
+			renderer.stopSpinner()
+			if syncOpts.DryRun || syncOpts.Yes ||
+				confirm("The local or remote folder is empty, but was synced before. Delete all items on the other side?") {
+				opts.AllowEmptySide = true
+				actions, err = sdk.PlanTwoWaySync(localDir, local, remote, state, opts)
+			}
+		}
+	} else {
+		actions, err = sdk.PlanSync(local, remote, opts)
+	}
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not plan sync: " + err.Error())
+		return
+	}
+	numConflicts := 0
+	for _, action := range actions {
+		if action.Conflict {
+			numConflicts++
+			print("CONFLICT " + formatSyncAction(action))
+		} else if syncOpts.DryRun {
+			print(formatSyncAction(action))
+		}
+	}
+	if syncOpts.DryRun {
+		log(strconv.Itoa(len(actions)) + " action(s) planned, " + strconv.Itoa(numConflicts) + " conflict(s).")
+		return
+	}
+	if state != nil && !syncOpts.Yes {
+		if n := state.CountLocalDeletes(actions); n > syncOpts.ConfirmAbove &&
+			!confirm(strconv.Itoa(n)+" local item(s) were deleted remotely and would be deleted locally. Continue?") {
+			logError("Aborted.")
+			return
+		}
+	}
+	trackTransfers(client, renderer, "Syncing")
+	for _, action := range actions {
+		logVerbose(formatSyncAction(action))
+		if err := client.EnsureAccessToken(); err != nil {
+			logError("Could not renew access token: " + err.Error())
+			return
+		}
+		if state != nil {
+			err = client.ExecuteTwoWaySyncAction(remoteDir, action, state)
+		} else {
+			err = client.ExecuteSyncAction(remoteDir, action)
+		}
+		if err != nil {
+			logError("Could not " + action.Type.String() + " " + action.Path + ": " + err.Error())
+			return
+		}
+	}
+	if state != nil {
+		state.RecordUnchanged(local, remote, actions)
+		if err := state.Write(); err != nil {
+			logError("Could not write sync state: " + err.Error())
+			return
+		}
+	}
+	log("Sync finished, " + strconv.Itoa(len(actions)) + " action(s) performed, " + strconv.Itoa(numConflicts) + " conflict(s).")
+}
+
+func formatSyncAction(action *sdk.SyncAction) string {
+	return action.Type.String() + " " + action.Path + " (" + action.Reason + ")"
+}
+
+func syncStateFilePath(client *sdk.Client, localDir, remoteDir string) string {
+	absLocalDir, err := filepath.Abs(localDir)
+	if err != nil {
+		absLocalDir = localDir
+	}
+	key := sha1.Sum([]byte(absLocalDir + "\n" + path.Clean("/"+remoteDir)))
+	configPath := client.Config.ConfigFilePath
+	return strings.TrimSuffix(configPath, filepath.Ext(configPath)) + ".sync-" + hex.EncodeToString(key[:8]) + ".json"
+}
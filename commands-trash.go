@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+var trashOpts struct {
+	To   string
+	Name string
+}
+
+func trashFlags(f *flag.FlagSet) {
+	f.StringVar(&trashOpts.To, "to", "", "restore into this folder instead of the original one")
+	f.StringVar(&trashOpts.Name, "name", "", "restore using this name")
+}
+
+func cmdTrash(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	switch args[0] {
+	case "ls":
+		cmdTrashList(client, renderer)
+	case "restore":
+		if len(args) < 2 {
+			printHelp()
+			return
+		}
+		cmdTrashRestore(client, renderer, args[1])
+	default:
+		printHelp()
+	}
+}
+
+// cmdTrashList prints the deleted items recorded in the local index. The
+// Graph API can't list the recycle bin of personal drives, so deletions are
+// taken from the delta queries updating the index and from rm.
+func cmdTrashList(client *sdk.Client, renderer *OutputRenderer) {
+	db, _ := openUpdatedStateDB(client, renderer, false)
+	if db == nil {
+		return
+	}
+	items := db.DeletedItems()
+	if len(items) == 0 {
+		log("No deleted items recorded (deletions are recorded by 'rm' and 'find --delete', and from the first run of 'trash ls', 'changes' or 'sync --index' on).")
+		return
+	}
+	for _, item := range items {
+		itemType := "f"
+		if item.IsFolder {
+			itemType = "d"
+		}
+		print(item.DeletedAt.Local().Format(time.DateTime) + "  " + itemType + "  " + item.ID + "  " + item.Path)
+	}
+}
+
+func cmdTrashRestore(client *sdk.Client, renderer *OutputRenderer, idOrPath string) {
+	id := idOrPath
+	db, err := sdk.OpenStateDB(indexFilePath(client))
+	if err != nil {
+		logError("Could not open local index: " + err.Error())
+		return
+	}
+	if strings.HasPrefix(idOrPath, "/") {
+		item := db.LookupDeleted(idOrPath)
+		if item == nil {
+			logError("No deleted item recorded for " + idOrPath + " (run 'trash ls' to update the list)")
+			return
+		}
+		id = item.ID
+	}
+	renderer.initSpinner("Restoring...")
+	item, err := client.Restore(id, trashOpts.To, trashOpts.Name)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not restore: " + err.Error())
+		return
+	}
+	delete(db.Deleted, id)
+	if err := db.Write(); err != nil {
+		logError("Could not write local index: " + err.Error())
+		return
+	}
+	log("Restored " + item.Name + ".")
+}
+
+// deletionRecorder records the items deleted by rm and find --delete in the
+// local index, so they can be restored even if the index has never seen
+// them. Recording is best effort and doesn't fail the deletion.
+type deletionRecorder struct {
+	db      *sdk.StateDB
+	changed bool
+}
+
+func newDeletionRecorder(client *sdk.Client) *deletionRecorder {
+	db, err := sdk.OpenStateDB(indexFilePath(client))
+	if err != nil {
+		log("Could not open local index, deletions are not recorded: " + err.Error())
+	}
+	return &deletionRecorder{db: db}
+}
+
+func (r *deletionRecorder) add(p string, item *sdk.DriveItem) {
+	if r.db != nil {
+		r.db.RecordDeleted(p, item)
+		r.changed = true
+	}
+}
+
+func (r *deletionRecorder) write() {
+	if !r.changed {
+		return
+	}
+	if err := r.db.Write(); err != nil {
+		log("Could not record deletions in local index: " + err.Error())
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+func cmdVersions(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	switch {
+	case args[0] == "ls" && len(args) == 2:
+		cmdVersionsList(client, renderer, args[1])
+	case args[0] == "get" && (len(args) == 3 || len(args) == 4):
+		target := ""
+		if len(args) == 4 {
+			target = args[3]
+		}
+		cmdVersionsGet(client, args[1], args[2], target)
+	case args[0] == "restore" && len(args) == 3:
+		cmdVersionsRestore(client, renderer, args[1], args[2])
+	default:
+		printHelp()
+	}
+}
+
+func cmdVersionsList(client *sdk.Client, renderer *OutputRenderer, remotePath string) {
+	renderer.initSpinner("Retrieving versions...")
+	versions, err := client.Versions(remotePath)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not get versions: " + err.Error())
+		return
+	}
+	rows := [][]string{{"ID", "MODIFIED", "SIZE", "MODIFIED BY"}}
+	for _, version := range versions {
+		rows = append(rows, []string{
+			version.ID,
+			version.LastModified.Local().Format(time.DateTime),
+			strconv.FormatInt(version.SizeBytes, 10),
+			version.LastModifiedBy.Name(),
+		})
+	}
+	printTable(rows)
+}
+
+// cmdVersionsGet writes the content of a version to target, which may be a
+// directory or a file name. Without target, the content is written to
+// stdout.
+func cmdVersionsGet(client *sdk.Client, remotePath, versionID, target string) {
+	if target == "" {
+		reader, err := client.OpenVersionContent(remotePath, versionID)
+		if err != nil {
+			logError("Could not download version: " + err.Error())
+			return
+		}
+		defer reader.Close()
+		if _, err := io.Copy(os.Stdout, reader); err != nil {
+			logError("Could not download version: " + err.Error())
+		}
+		return
+	}
+	if err := client.DownloadVersion(remotePath, versionID, target); err != nil {
+		logError("Could not download version: " + err.Error())
+		return
+	}
+	log("Version " + versionID + " downloaded.")
+}
+
+func cmdVersionsRestore(client *sdk.Client, renderer *OutputRenderer, remotePath, versionID string) {
+	renderer.initSpinner("Restoring version...")
+	err := client.RestoreVersion(remotePath, versionID)
+	renderer.stopSpinner()
+	if err != nil {
+		logError("Could not restore version: " + err.Error())
+		return
+	}
+	log("Version " + versionID + " restored.")
+}
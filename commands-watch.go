@@ -0,0 +1,257 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+const (
+	watchRetryBackoff    = 5 * time.Second
+	watchMaxRetryBackoff = 5 * time.Minute
+)
+
+type watchedFile struct {
+	LastEvent time.Time
+	Size      int64
+	ModTime   time.Time
+	Retries   int
+	NextRetry time.Time
+}
+
+type watcher struct {
+	client    *sdk.Client
+	renderer  *OutputRenderer
+	fsWatcher *fsnotify.Watcher
+	localDir  string
+	remoteDir string
+	moveDir   string
+	pending   map[string]*watchedFile
+}
+
+var watchOpts struct {
+	Settle      time.Duration
+	DeleteAfter bool
+	MoveAfter   string
+	Conflict    conflictOptions
+}
+
+func watchFlags(f *flag.FlagSet) {
+	f.DurationVar(&watchOpts.Settle, "settle", 2*time.Second, "time without writes before a file is uploaded")
+	f.BoolVar(&watchOpts.DeleteAfter, "delete-after", false, "delete local file after verified upload")
+	f.StringVar(&watchOpts.MoveAfter, "move-after", "", "move local file to this directory after verified upload")
+	watchOpts.Conflict.register(f)
+}
+
+func cmdWatch(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	if watchOpts.DeleteAfter && watchOpts.MoveAfter != "" {
+		logError("Please specify either --delete-after or --move-after")
+		return
+	}
+	watchOpts.Conflict.apply(client)
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logError("Could not start watching: " + err.Error())
+		return
+	}
+	defer fsWatcher.Close()
+	w := &watcher{
+		client:    client,
+		renderer:  renderer,
+		fsWatcher: fsWatcher,
+		localDir:  filepath.Clean(args[0]),
+		remoteDir: args[1],
+		pending:   make(map[string]*watchedFile),
+	}
+	if watchOpts.MoveAfter != "" {
+		w.moveDir, _ = filepath.Abs(watchOpts.MoveAfter)
+	}
+	if err := w.addDir(w.localDir, false); err != nil {
+		logError("Could not watch " + w.localDir + ": " + err.Error())
+		return
+	}
+	trackTransfers(client, renderer, "Uploading")
+	log("Watching " + w.localDir + " for new files...")
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			w.handleEvent(event)
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			log("Watch error: " + err.Error())
+		case <-ticker.C:
+			w.processPending()
+		}
+	}
+}
+
+// addDir watches dir and all its sub directories. If markFiles is set, files
+// already existing in the directories are queued for uploading.
+func (w *watcher) addDir(dir string, markFiles bool) error {
+	return filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if w.isIgnored(p) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			logVerbose("Watching " + p)
+			return w.fsWatcher.Add(p)
+		}
+		if markFiles && d.Type().IsRegular() {
+			w.markPending(p)
+		}
+		return nil
+	})
+}
+
+func (w *watcher) isIgnored(p string) bool {
+	if p != w.localDir && strings.HasPrefix(filepath.Base(p), ".") {
+		return true
+	}
+	if w.moveDir != "" {
+		if abs, err := filepath.Abs(p); err == nil && (abs == w.moveDir || strings.HasPrefix(abs, w.moveDir+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *watcher) markPending(p string) {
+	item := w.pending[p]
+	if item == nil {
+		item = &watchedFile{}
+		w.pending[p] = item
+	}
+	item.LastEvent = time.Now()
+}
+
+func (w *watcher) handleEvent(event fsnotify.Event) {
+	if w.isIgnored(event.Name) {
+		return
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		delete(w.pending, event.Name)
+		return
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+	info, err := os.Stat(event.Name)
+	if err != nil {
+		return
+	}
+	if info.IsDir() {
+		if event.Has(fsnotify.Create) {
+			if err := w.addDir(event.Name, true); err != nil {
+				log("Could not watch " + event.Name + ": " + err.Error())
+			}
+		}
+		return
+	}
+	if info.Mode().IsRegular() {
+		w.markPending(event.Name)
+	}
+}
+
+// processPending uploads all files which haven't been written to for the
+// settle time and whose size and modification time didn't change since the
+// last check.
+func (w *watcher) processPending() {
+	now := time.Now()
+	for p, item := range w.pending {
+		if now.Sub(item.LastEvent) < watchOpts.Settle || now.Before(item.NextRetry) {
+			continue
+		}
+		info, err := os.Stat(p)
+		if err != nil {
+			delete(w.pending, p)
+			continue
+		}
+		if info.Size() != item.Size || !info.ModTime().Equal(item.ModTime) {
+			item.Size = info.Size()
+			item.ModTime = info.ModTime()
+			item.LastEvent = now
+			continue
+		}
+		if err := w.upload(p); err != nil {
+			if _, ok := err.(*sdk.ConflictError); ok {
+				// Retrying won't help
+				log("Skipped " + p + ": " + err.Error())
+				delete(w.pending, p)
+				continue
+			}
+			item.Retries++
+			backoff := watchRetryDelay(item.Retries)
+			log("Could not upload " + p + " (retrying in " + backoff.String() + "): " + err.Error())
+			item.NextRetry = now.Add(backoff)
+			continue
+		}
+		delete(w.pending, p)
+	}
+}
+
+// watchRetryDelay returns the delay before the given retry, doubling with
+// each retry up to watchMaxRetryBackoff. Files are retried until they have
+// been uploaded, so they aren't lost during longer network outages.
+func watchRetryDelay(retries int) time.Duration {
+	backoff := watchRetryBackoff
+	for i := 1; i < retries && backoff < watchMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, watchMaxRetryBackoff)
+}
+
+func (w *watcher) upload(localPath string) error {
+	if err := w.client.EnsureAccessToken(); err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(w.localDir, localPath)
+	if err != nil {
+		return err
+	}
+	targetFolder := path.Join("/", w.remoteDir, filepath.ToSlash(filepath.Dir(rel)))
+	item, err := w.client.UploadItem(localPath, targetFolder)
+	if err != nil {
+		return err
+	}
+	log("Uploaded " + rel)
+	if !watchOpts.DeleteAfter && w.moveDir == "" {
+		return nil
+	}
+	// The item may have been renamed due to --on-conflict=rename
+	remotePath := path.Join(targetFolder, item.Name)
+	ok, err := w.client.RemoteFileMatches(localPath, remotePath)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log("Verification of " + rel + " failed, keeping local file")
+		return nil
+	}
+	if watchOpts.DeleteAfter {
+		return os.Remove(localPath)
+	}
+	target := filepath.Join(w.moveDir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	return os.Rename(localPath, target)
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchRetryDelay(t *testing.T) {
+	checkTestBool(t, true, watchRetryDelay(1) == 5*time.Second)
+	checkTestBool(t, true, watchRetryDelay(2) == 10*time.Second)
+	checkTestBool(t, true, watchRetryDelay(4) == 40*time.Second)
+	checkTestBool(t, true, watchRetryDelay(7) == 5*time.Minute)
+	checkTestBool(t, true, watchRetryDelay(1000) == 5*time.Minute)
+}
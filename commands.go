@@ -1,12 +1,21 @@
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
+	"math"
 	"os"
+	"path"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/virtualzone/onedrive-uploader/sdk"
@@ -19,17 +28,30 @@ type CommandFunctionDefinition struct {
 	MinArgs         int
 	InitSecretStore bool
 	RequireConfig   bool
+	Flags           func(f *flag.FlagSet)
 }
 
 var (
 	commands = map[string]*CommandFunctionDefinition{
 		"config":   {Fn: cmdConfig, MinArgs: 0, InitSecretStore: false, RequireConfig: false},
-		"login":    {Fn: cmdLogin, MinArgs: 0, InitSecretStore: false, RequireConfig: true},
-		"mkdir":    {Fn: cmdCreateDir, MinArgs: 1, InitSecretStore: true, RequireConfig: true},
-		"upload":   {Fn: cmdUpload, MinArgs: 2, InitSecretStore: true, RequireConfig: true},
-		"download": {Fn: cmdDownload, MinArgs: 2, InitSecretStore: true, RequireConfig: true},
-		"rm":       {Fn: cmdDelete, MinArgs: 1, InitSecretStore: true, RequireConfig: true},
-		"ls":       {Fn: cmdList, MinArgs: 1, InitSecretStore: true, RequireConfig: true},
+		"login":    {Fn: cmdLogin, MinArgs: 0, InitSecretStore: false, RequireConfig: true, Flags: loginFlags},
+		"mkdir":    {Fn: cmdCreateDir, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: mkdirFlags},
+		"upload":   {Fn: cmdUpload, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: uploadFlags},
+		"sync":     {Fn: cmdSync, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: syncFlags},
+		"watch":    {Fn: cmdWatch, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: watchFlags},
+		"download": {Fn: cmdDownload, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: downloadFlags},
+		"cat":      {Fn: cmdCat, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: catFlags},
+		"mv":       {Fn: cmdMove, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: moveFlags},
+		"cp":       {Fn: cmdCopy, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: copyFlags},
+		"rm":       {Fn: cmdDelete, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: deleteFlags},
+		"trash":    {Fn: cmdTrash, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: trashFlags},
+		"ls":       {Fn: cmdList, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: listFlags},
+		"changes":  {Fn: cmdChanges, MinArgs: 0, InitSecretStore: true, RequireConfig: true, Flags: changesFlags},
+		"versions": {Fn: cmdVersions, MinArgs: 2, InitSecretStore: true, RequireConfig: true},
+		"share":    {Fn: cmdShare, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: shareFlags},
+		"perm":     {Fn: cmdPerm, MinArgs: 2, InitSecretStore: true, RequireConfig: true, Flags: permFlags},
+		"find":     {Fn: cmdFind, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: findFlags},
+		"search":   {Fn: cmdSearch, MinArgs: 1, InitSecretStore: true, RequireConfig: true, Flags: searchFlags},
 		"info":     {Fn: cmdInfo, MinArgs: 1, InitSecretStore: true, RequireConfig: true},
 		"sha1":     {Fn: cmdSHA1, MinArgs: 1, InitSecretStore: true, RequireConfig: true},
 		"sha256":   {Fn: cmdSHA256, MinArgs: 1, InitSecretStore: true, RequireConfig: true},
@@ -102,13 +124,29 @@ func cmdMigrateConfig(client *sdk.Client, renderer *OutputRenderer, args []strin
 	log("Configuration migrated.")
 }
 
+var loginOpts struct {
+	Paste bool
+}
+
+func loginFlags(f *flag.FlagSet) {
+	f.BoolVar(&loginOpts.Paste, "paste", false, "paste redirect URL or code instead of waiting for the callback")
+}
+
 func cmdLogin(client *sdk.Client, renderer *OutputRenderer, args []string) {
 	log("------------------------------------")
 	log("Open a browser and go to:")
 	print(client.GetLoginURL())
 	log("------------------------------------")
-	renderer.initSpinner("Waiting for code...")
-	err := client.Login()
+	var err error
+	if loginOpts.Paste {
+		log("After logging in, your browser is redirected to a page which may fail to load.")
+		input := promptString("Paste the full URL from the browser's address bar (or the code): ")
+		renderer.initSpinner("Redeeming code...")
+		err = client.LoginWithCode(input)
+	} else {
+		renderer.initSpinner("Waiting for code...")
+		err = client.Login()
+	}
 	renderer.stopSpinner()
 	if err != nil {
 		logError("Could not log in: " + err.Error())
@@ -117,7 +155,16 @@ func cmdLogin(client *sdk.Client, renderer *OutputRenderer, args []string) {
 	log("Login successful.")
 }
 
+var mkdirOpts struct {
+	Conflict conflictOptions
+}
+
+func mkdirFlags(f *flag.FlagSet) {
+	mkdirOpts.Conflict.register(f)
+}
+
 func cmdCreateDir(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	mkdirOpts.Conflict.apply(client)
 	renderer.initSpinner("Creating directory...")
 	err := client.CreateDir(args[0])
 	renderer.stopSpinner()
@@ -128,9 +175,94 @@ func cmdCreateDir(client *sdk.Client, renderer *OutputRenderer, args []string) {
 	log("Folder created.")
 }
 
+// conflictOptions holds the --on-conflict flag of commands creating remote items.
+type conflictOptions struct {
+	OnConflict string
+}
+
+func (o *conflictOptions) register(f *flag.FlagSet) {
+	f.StringVar(&o.OnConflict, "on-conflict", "", "behavior if remote item exists (fail, replace, rename)")
+}
+
+func (o *conflictOptions) apply(client *sdk.Client) {
+	switch sdk.ConflictBehavior(o.OnConflict) {
+	case "", sdk.ConflictBehaviorFail, sdk.ConflictBehaviorReplace, sdk.ConflictBehaviorRename:
+		client.ConflictBehavior = sdk.ConflictBehavior(o.OnConflict)
+	default:
+		logError("Invalid conflict behavior: " + o.OnConflict)
+	}
+}
+
+// verifyOptions holds the integrity check flags of commands transferring files.
+type verifyOptions struct {
+	Verify        bool
+	DeleteCorrupt bool
+}
+
+func (o *verifyOptions) register(f *flag.FlagSet) {
+	f.BoolVar(&o.Verify, "verify", false, "verify hash after transfer")
+	f.BoolVar(&o.DeleteCorrupt, "delete-corrupt", false, "delete transferred file if verification fails")
+}
+
+func (o *verifyOptions) apply(client *sdk.Client) {
+	client.VerifyTransfers = o.Verify
+	client.DeleteCorruptFiles = o.DeleteCorrupt
+}
+
+var uploadOpts struct {
+	IfChanged bool
+	As        string
+	Verify    verifyOptions
+	Conflict  conflictOptions
+}
+
+func uploadFlags(f *flag.FlagSet) {
+	f.BoolVar(&uploadOpts.IfChanged, "if-changed", false, "skip files if remote file has same size and hash")
+	f.StringVar(&uploadOpts.As, "as", "", "upload a single file under this name")
+	uploadOpts.Verify.register(f)
+	uploadOpts.Conflict.register(f)
+}
+
+var downloadOpts struct {
+	Parallel  int
+	NoClobber bool
+	Backup    bool
+	Verify    verifyOptions
+}
+
+func downloadFlags(f *flag.FlagSet) {
+	f.IntVar(&downloadOpts.Parallel, "parallel", 1, "number of connections for downloading large files")
+	f.BoolVar(&downloadOpts.NoClobber, "no-clobber", false, "fail if local file exists")
+	f.BoolVar(&downloadOpts.Backup, "backup", false, "rename existing local file to <name>~ (or <name>.~1~ etc.)")
+	downloadOpts.Verify.register(f)
+}
+
 func cmdUpload(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	uploadOpts.Verify.apply(client)
+	uploadOpts.Conflict.apply(client)
 	targetFolder := args[len(args)-1]
 	sourceFiles := args[:len(args)-1]
+	remoteName := uploadOpts.As
+	if remoteName != "" {
+		if len(sourceFiles) != 1 {
+			logError("--as can only be used when uploading a single file")
+			return
+		}
+		if strings.Contains(remoteName, "/") {
+			logError("--as must be a file name, not a path")
+			return
+		}
+	} else if len(sourceFiles) == 1 {
+		isFile, err := isRemoteFile(client, targetFolder)
+		if err != nil {
+			logError("Could not get info for target: " + err.Error())
+			return
+		}
+		if isFile {
+			remoteName = path.Base(targetFolder)
+			targetFolder = path.Dir("/" + strings.TrimPrefix(targetFolder, "/"))
+		}
+	}
 	numFiles := 0
 	for _, sourceFile := range sourceFiles {
 		fileStat, err := os.Stat(sourceFile)
@@ -144,22 +276,23 @@ func cmdUpload(client *sdk.Client, renderer *OutputRenderer, args []string) {
 		}
 		// Upload file
 		numFiles++
-		client.ResetChannels()
-		done := false
-		go func() {
-			fileStat := <-client.ChannelTransferStart
-			renderer.initProgressBar(fileStat.Size(), "Uploading "+fmt.Sprintf("%-20s", cutString(fileStat.Name(), 17)+"..."))
-		}()
-		go func() {
-			for !done {
-				bytes := <-client.ChannelTransferProgress
-				renderer.updateProgressBar(bytes)
+		trackUploadProgress(client, renderer)
+		fileName := remoteName
+		if fileName == "" {
+			fileName = filepath.Base(sourceFile)
+		}
+		if uploadOpts.IfChanged {
+			uploaded, err := client.UploadAsIfChanged(sourceFile, targetFolder, fileName)
+			if err != nil {
+				logError("Could not upload file: " + err.Error())
+				return
 			}
-		}()
-		go func() {
-			done = <-client.ChannelTransferFinish
-		}()
-		err = client.Upload(sourceFile, targetFolder)
+			if !uploaded {
+				log("Skipped " + fileStat.Name() + " (unchanged)")
+			}
+			continue
+		}
+		err = client.UploadAs(sourceFile, targetFolder, fileName)
 		if err != nil {
 			logError("Could not upload file: " + err.Error())
 			return
@@ -170,7 +303,160 @@ func cmdUpload(client *sdk.Client, renderer *OutputRenderer, args []string) {
 	}
 }
 
+// isRemoteFile reports whether the upload target is an existing file, which
+// is then replaced. All other targets are folders, which are created if they
+// don't exist. Targets ending with a slash are always folders.
+func isRemoteFile(client *sdk.Client, target string) (bool, error) {
+	if strings.HasSuffix(target, "/") || path.Base("/"+target) == "/" {
+		return false, nil
+	}
+	info, err := client.Info(target)
+	if err == sdk.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.Type == sdk.DriveItemTypeFile, nil
+}
+
+var moveOpts struct {
+	Conflict conflictOptions
+}
+
+func moveFlags(f *flag.FlagSet) {
+	moveOpts.Conflict.register(f)
+}
+
+func cmdMove(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	moveOpts.Conflict.apply(client)
+	sources := args[:len(args)-1]
+	targets, err := targetPaths(client, sources, args[len(args)-1], true)
+	if err != nil {
+		logError("Could not get info for target: " + err.Error())
+		return
+	}
+	for i, source := range sources {
+		renderer.initSpinner("Moving " + source + "...")
+		err := client.Move(source, targets[i])
+		renderer.stopSpinner()
+		if err != nil {
+			logError("Could not move " + source + ": " + err.Error())
+			return
+		}
+		log("Moved " + source + " to " + targets[i])
+	}
+}
+
+var copyOpts struct {
+	DriveID  string
+	Conflict conflictOptions
+}
+
+func copyFlags(f *flag.FlagSet) {
+	f.StringVar(&copyOpts.DriveID, "drive-id", "", "ID of the target drive (default: own drive)")
+	copyOpts.Conflict.register(f)
+}
+
+func cmdCopy(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	copyOpts.Conflict.apply(client)
+	sources := args[:len(args)-1]
+	// Targets in other drives can't be looked up
+	targets, err := targetPaths(client, sources, args[len(args)-1], copyOpts.DriveID == "")
+	if err != nil {
+		logError("Could not get info for target: " + err.Error())
+		return
+	}
+	trackTransfers(client, renderer, "Copying")
+	for i, source := range sources {
+		err := client.Copy(source, targets[i], copyOpts.DriveID)
+		if err != nil {
+			logError("Could not copy " + source + ": " + err.Error())
+			return
+		}
+		log("Copied " + source + " to " + targets[i])
+	}
+}
+
+// targetPaths returns the destination path of each source for mv and cp.
+// The sources are placed in the target folder if there are several of them,
+// if the target ends with a slash or, if lookup is set, if the target is an
+// existing folder. Otherwise, the target is the new path of the only source.
+func targetPaths(client *sdk.Client, sources []string, target string, lookup bool) ([]string, error) {
+	intoFolder := len(sources) > 1 || strings.HasSuffix(target, "/")
+	if !intoFolder && lookup {
+		info, err := client.Info(target)
+		if err != nil && err != sdk.ErrNotFound {
+			return nil, err
+		}
+		intoFolder = err == nil && info.Type == sdk.DriveItemTypeFolder
+	}
+	res := make([]string, len(sources))
+	for i, source := range sources {
+		res[i] = target
+		if intoFolder {
+			res[i] = path.Join("/", target, path.Base(source))
+		}
+	}
+	return res, nil
+}
+
+func trackUploadProgress(client *sdk.Client, renderer *OutputRenderer) {
+	client.ResetChannels()
+	done := false
+	go func() {
+		fileStat := <-client.ChannelTransferStart
+		if fileStat == nil {
+			return
+		}
+		renderer.initProgressBar(fileStat.Size(), "Uploading "+fmt.Sprintf("%-20s", cutString(fileStat.Name(), 17)+"..."))
+	}()
+	go func() {
+		for !done {
+			bytes, ok := <-client.ChannelTransferProgress
+			if !ok {
+				return
+			}
+			renderer.updateProgressBar(bytes)
+		}
+	}()
+	go func() {
+		done = <-client.ChannelTransferFinish
+	}()
+}
+
+// trackTransfers renders the progress of all transfers performed by the
+// client until the program exits.
+func trackTransfers(client *sdk.Client, renderer *OutputRenderer, verb string) {
+	go func() {
+		for {
+			select {
+			case fileStat := <-client.ChannelTransferStart:
+				if fileStat != nil {
+					renderer.initProgressBar(fileStat.Size(), verb+" "+fmt.Sprintf("%-20s", cutString(fileStat.Name(), 17)+"..."))
+				}
+			case bytes := <-client.ChannelTransferProgress:
+				renderer.updateProgressBar(bytes)
+			case <-client.ChannelTransferFinish:
+			}
+		}
+	}()
+}
+
 func cmdDownload(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	if downloadOpts.NoClobber && downloadOpts.Backup {
+		logError("Please specify either --no-clobber or --backup")
+		return
+	}
+	if downloadOpts.Parallel > 0 {
+		client.DownloadConnections = downloadOpts.Parallel
+	}
+	if downloadOpts.NoClobber {
+		client.ExistingFiles = sdk.ExistingFileNoClobber
+	} else if downloadOpts.Backup {
+		client.ExistingFiles = sdk.ExistingFileBackup
+	}
+	downloadOpts.Verify.apply(client)
 	done := false
 	go func() {
 		var fileStat fs.FileInfo = nil
@@ -201,31 +487,26 @@ func cmdDownload(client *sdk.Client, renderer *OutputRenderer, args []string) {
 	log("File downloaded.")
 }
 
-func cmdDelete(client *sdk.Client, renderer *OutputRenderer, args []string) {
-	renderer.initSpinner("Deleting...")
-	err := client.Delete(args[0])
-	renderer.stopSpinner()
-	if err != nil {
-		logError("Could not delete: " + err.Error())
-		return
-	}
-	log("Deleted.")
+var catOpts struct {
+	Offset int64
+	Length int64
 }
 
-func cmdList(client *sdk.Client, renderer *OutputRenderer, args []string) {
-	renderer.initSpinner("Retrieving directory listing...")
-	list, err := client.List(args[0])
-	renderer.stopSpinner()
+func catFlags(f *flag.FlagSet) {
+	f.Int64Var(&catOpts.Offset, "offset", 0, "start at byte offset")
+	f.Int64Var(&catOpts.Length, "length", 0, "number of bytes to write (0 = until end)")
+}
+
+func cmdCat(client *sdk.Client, renderer *OutputRenderer, args []string) {
+	reader, err := client.OpenContent(args[0], catOpts.Offset, catOpts.Length)
 	if err != nil {
-		logError("Could not list: " + err.Error())
+		logError("Could not download file: " + err.Error())
 		return
 	}
-	for _, item := range list {
-		itemType := "d"
-		if item.File.MimeType != "" {
-			itemType = "f"
-		}
-		print(itemType + " " + item.Name)
+	defer reader.Close()
+	if _, err := io.Copy(os.Stdout, reader); err != nil {
+		logError("Could not download file: " + err.Error())
+		return
 	}
 }
 
@@ -279,6 +560,100 @@ func cmdVersion(client *sdk.Client, renderer *OutputRenderer, args []string) {
 	print(AppVersion)
 }
 
+func promptString(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	reader := bufio.NewReader(os.Stdin)
+	s, _ := reader.ReadString('\n')
+	s = strings.TrimSuffix(s, "\n")
+	s = strings.TrimSuffix(s, "\r")
+	return s
+}
+
+// parseTimeSpec parses an absolute date in the formats "2006-01-02" or RFC
+// 3339, or a duration in days (e.g. "7d") or in the format of
+// time.ParseDuration (e.g. "12h"). Durations are added to now in the
+// direction of sign, so -1 refers to the past.
+func parseTimeSpec(s string, now time.Time, sign int) (time.Time, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return time.Time{}, errors.New("invalid number of days: " + s)
+		}
+		return now.AddDate(0, 0, sign*n), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.New("duration must be positive: " + s)
+		}
+		return now.Add(time.Duration(sign) * d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, errors.New("unsupported format: " + s)
+}
+
+// parseSize parses a size in bytes with an optional unit (K, M, G or T,
+// using powers of 1024).
+func parseSize(s string) (int64, error) {
+	units := "KMGT"
+	number := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	var multiplier int64 = 1
+	if n := len(number); n > 0 {
+		if i := strings.IndexByte(units, number[n-1]); i >= 0 {
+			multiplier = 1 << (10 * (i + 1))
+			number = number[:n-1]
+		}
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || !(value >= 0) || math.IsInf(value, 0) {
+		return 0, errors.New("invalid size: " + s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// humanSize formats a size in bytes using the units K, M, G and T (powers
+// of 1024), e.g. "1.5G".
+func humanSize(size int64) string {
+	if size < 1024 {
+		return strconv.FormatInt(size, 10)
+	}
+	units := "KMGT"
+	value := float64(size)
+	unit := -1
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if value < 10 {
+		return strconv.FormatFloat(value, 'f', 1, 64) + string(units[unit])
+	}
+	return strconv.FormatFloat(value, 'f', 0, 64) + string(units[unit])
+}
+
+// printTable prints the rows as columns aligned with spaces.
+func printTable(rows [][]string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
+// confirm asks a yes/no question, defaulting to no. If stdin is not a
+// terminal, nobody can answer, so the question is declined right away.
+func confirm(question string) bool {
+	if stat, err := os.Stdin.Stat(); err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintln(os.Stderr, question+" Declined, input is not a terminal (use --yes to skip confirmation).")
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(promptString(question + " [y/N] ")))
+	return answer == "y" || answer == "yes"
+}
+
 func cutString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	for input, expected := range map[string]int64{
+		"0":     0,
+		"512":   512,
+		"1K":    1024,
+		"1kb":   1024,
+		"1.5M":  3 << 19,
+		"500M":  500 << 20,
+		" 1G ":  1 << 30,
+		"2T":    2 << 40,
+		"100B":  100,
+		"0.5K":  512,
+		"1e3":   1000,
+		"10.0G": 10 << 30,
+	} {
+		size, err := parseSize(input)
+		checkTestBool(t, true, err == nil)
+		checkTestBool(t, true, size == expected)
+	}
+	for _, input := range []string{"", "G", "-1K", "1P", "abc", "NaN", "Inf", "1,5M"} {
+		_, err := parseSize(input)
+		checkTestBool(t, true, err != nil)
+	}
+}
+
+func TestParseTimeSpec(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	res, err := parseTimeSpec("30d", now, -1)
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, res.Equal(time.Date(2026, 9, 19, 12, 0, 0, 0, time.UTC)))
+
+	res, err = parseTimeSpec("7d", now, 1)
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, res.Equal(time.Date(2026, 10, 26, 12, 0, 0, 0, time.UTC)))
+
+	res, err = parseTimeSpec("12h", now, -1)
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, res.Equal(now.Add(-12*time.Hour)))
+
+	res, err = parseTimeSpec("2026-01-31", now, -1)
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, res.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local)))
+
+	res, err = parseTimeSpec("2026-01-31T10:00:00+02:00", now, -1)
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, res.Equal(time.Date(2026, 1, 31, 8, 0, 0, 0, time.UTC)))
+
+	for _, input := range []string{"", "0d", "-3d", "xd", "-1h", "0s", "31.01.2026", "yesterday"} {
+		_, err := parseTimeSpec(input, now, -1)
+		checkTestBool(t, true, err != nil)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	for size, expected := range map[int64]string{
+		0:           "0",
+		1023:        "1023",
+		1024:        "1.0K",
+		1536:        "1.5K",
+		10 * 1024:   "10K",
+		1000 * 1024: "1000K",
+		1024 * 1024: "1.0M",
+		3 << 29:     "1.5G",
+		500 << 30:   "500G",
+		2 << 40:     "2.0T",
+		2048 << 40:  "2048T",
+	} {
+		checkTestString(t, expected, humanSize(size))
+	}
+}
+
+func TestIsRemoteFile(t *testing.T) {
+	client := startTestGraphServer(t, map[string]string{
+		"/me/drive/root:/backup/latest.tar.gz": `{"name":"latest.tar.gz","file":{"mimeType":"application/gzip"}}`,
+		"/me/drive/root:/releases":             `{"name":"releases","folder":{"childCount":0}}`,
+	})
+	for target, expected := range map[string]bool{
+		"backup/latest.tar.gz":  true,
+		"/backup/latest.tar.gz": true,
+		"backup/latest.tar.gz/": false,
+		"releases":              false,
+		"releases/v1.2":         false,
+		"new.txt":               false,
+		"/":                     false,
+	} {
+		isFile, err := isRemoteFile(client, target)
+		checkTestBool(t, true, err == nil)
+		checkTestBool(t, expected, isFile)
+	}
+}
@@ -3,6 +3,7 @@ module github.com/virtualzone/onedrive-uploader
 go 1.25.0
 
 require (
+	github.com/fsnotify/fsnotify v1.10.1
 	github.com/google/uuid v1.3.0
 	github.com/schollz/progressbar/v3 v3.19.0
 )
@@ -17,7 +17,7 @@ func (c *InteractiveConfig) readChar() rune {
 	reader := bufio.NewReader(os.Stdin)
 	char, _, err := reader.ReadRune()
 	if err != nil {
-		fmt.Printf("error reading from input: " + err.Error())
+		fmt.Print("error reading from input: " + err.Error())
 		os.Exit(1)
 	}
 	return char
@@ -122,7 +122,7 @@ func (c *InteractiveConfig) promptRedirectURL(config *sdk.Config) {
 func (c *InteractiveConfig) promptSave(config *sdk.Config) {
 	save := ""
 	for save == "" {
-		fmt.Printf("Save config? [" + c.TargetPath + "] ")
+		fmt.Print("Save config? [" + c.TargetPath + "] ")
 		save = c.readString()
 		if save == "" {
 			save = c.TargetPath
@@ -25,12 +25,56 @@ var (
 func printHelp() {
 	flag.Usage()
 	print("  config                             create config")
-	print("  login                              perform login")
-	print("  mkdir path                         create remote directory <path>")
-	print("  ls path                            list items in <path>")
-	print("  rm path                            delete <path>")
-	print("  upload localFile path              upload <localFile> to <path>")
-	print("  download sourceFile localPath      download <sourceFile> to <localPath>")
+	print("  login [--paste]                    perform login (--paste: enter redirect URL manually)")
+	print("  mkdir [--on-conflict=behavior] path")
+	print("                                     create remote directory <path>")
+	print("  ls [-l [-h] [--hashes]] [--sort=name|size|date] [-R | --tree] [-a] path")
+	print("                                     list items in <path> (-l: long format, -R: recursive)")
+	print("                                     (-a: mark special folders, packages and deleted items)")
+	print("  cat [--offset=n] [--length=n] path write content of <path> to stdout")
+	print("  mv [--on-conflict=behavior] source... path")
+	print("                                     move or rename <source> to <path> (file or folder)")
+	print("  cp [--drive-id=id] [--on-conflict=behavior] source... path")
+	print("                                     copy <source> to <path> on the server (file or folder)")
+	print("  rm [--dry-run] [--permanent] [--confirm-above=n] [--yes] path...")
+	print("                                     delete <path> (may contain wildcards, e.g. '/logs/*.tmp')")
+	print("  upload [--if-changed] [--as=name] [--verify [--delete-corrupt]] [--on-conflict=behavior] localFile path")
+	print("                                     upload <localFile> to folder <path> or replace file <path>")
+	print("                                     (--as: store as <name> in folder <path>)")
+	print("                                     (--if-changed: skip unchanged files)")
+	print("                                     (behavior if remote item exists: fail, replace, rename)")
+	print("  download [--parallel=n] [--verify [--delete-corrupt]] [--no-clobber | --backup] sourceFile localPath")
+	print("                                     download <sourceFile> to <localPath> (directory or file name)")
+	print("  watch [--settle=2s] [--delete-after | --move-after=dir] [--on-conflict=behavior] localDir path")
+	print("                                     upload files appearing in <localDir> to <path>")
+	print("  sync [--delete] [--dry-run] [--index] localDir path")
+	print("                                     upload new and changed files in <localDir> to <path>")
+	print("  sync --two-way [--conflict=policy] [--dry-run] [--index] [--confirm-above=n] [--yes] localDir path")
+	print("                                     sync changes in both directions (policy: keep-both,")
+	print("                                     newest-wins, local-wins, remote-wins)")
+	print("  trash ls                           list deleted items recorded in local index (deleted by rm")
+	print("                                     or find --delete, or seen by delta queries since index creation)")
+	print("  trash restore [--to=path] [--name=name] id|path")
+	print("                                     restore deleted item by ID or original path")
+	print("  changes [--reset]                  show remote changes since last run (using local index)")
+	print("  versions ls path                   list versions of file <path>")
+	print("  versions get path version [localPath]")
+	print("                                     download <version> of <path> (default: to stdout)")
+	print("  versions restore path version      make <version> the current version of <path>")
+	print("  find [--name=pattern] [--regex=expr] [--type=file|folder] [--min-size=n] [--max-size=n]")
+	print("       [--modified-before=t] [--modified-after=t] [--print0 | --jsonl | --delete [--yes]] path")
+	print("                                     find items below <path> (sizes e.g. 1G, times e.g. 30d, 2026-01-31)")
+	print("                                     (--delete: only files unless --type=folder is given)")
+	print("  search [--in=path] [--type=file|folder] query")
+	print("                                     search remote items matching <query>")
+	print("  share [--type=view] [--scope=scope] [--expires=7d] [--password=pw] path")
+	print("                                     create sharing link for <path> and print its URL")
+	print("  share ls path                      list sharing links of <path>")
+	print("  share revoke path id               revoke sharing link <id> of <path>")
+	print("  perm ls [--json] path              list permissions of <path>")
+	print("  perm invite [--role=read] [--message=text] [--no-email] [--json] path email...")
+	print("                                     grant recipients access to <path> (role: read, write)")
+	print("  perm rm path id                    remove permission <id> from <path>")
 	print("  info path                          show info about <path>")
 	print("  sha1 path                          get SHA1 hash for <path>")
 	print("  sha256 path                        get SHA256 hash for <path>")
@@ -47,9 +91,29 @@ func prepareFlags() {
 	flag.Parse()
 }
 
+// parseCommandFlags parses the command specific flags, which may be placed
+// anywhere between the command's positional arguments. All arguments after
+// a "--" are treated as positional, even if they start with a dash.
+func parseCommandFlags(flags *flag.FlagSet, args []string) []string {
+	res := []string{}
+	for {
+		flags.Parse(args)
+		rest := flags.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(res, rest...)
+		}
+		res = append(res, rest[0])
+		args = rest[1:]
+	}
+	return res
+}
+
 func logVerbose(s string) {
 	if AppFlags.Verbose {
-		fmt.Println(s)
+		fmt.Fprintln(os.Stderr, s)
 	}
 }
 
@@ -64,7 +128,7 @@ func log(s string) {
 }
 
 func logError(s string) {
-	fmt.Println(s)
+	fmt.Fprintln(os.Stderr, s)
 	os.Exit(1)
 }
 
@@ -107,6 +171,11 @@ func main() {
 	if flag.NArg() > 1 {
 		args = flag.Args()[1:]
 	}
+	if cmdDef.Flags != nil {
+		cmdFlags := flag.NewFlagSet(cmd, flag.ExitOnError)
+		cmdDef.Flags(cmdFlags)
+		args = parseCommandFlags(cmdFlags, args)
+	}
 	if len(args) < cmdDef.MinArgs {
 		printHelp()
 		return
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"github.com/virtualzone/onedrive-uploader/sdk"
+)
+
+func checkTestBool(t *testing.T, expected, actual bool) {
+	if expected != actual {
+		t.Fatalf("Expected '%t', but got '%t' at:\n%s", expected, actual, debug.Stack())
+	}
+}
+
+func checkTestString(t *testing.T, expected, actual string) {
+	if expected != actual {
+		t.Fatalf("Expected '%s', but got '%s' at:\n%s", expected, actual, debug.Stack())
+	}
+}
+
+func checkTestInt(t *testing.T, expected, actual int) {
+	if expected != actual {
+		t.Fatalf("Expected '%d', but got '%d' at:\n%s", expected, actual, debug.Stack())
+	}
+}
+
+// newTestClient returns a client talking to a test server with the given
+// handler instead of the Graph API.
+func newTestClient(t *testing.T, handler http.Handler) *sdk.Client {
+	ts := httptest.NewServer(handler)
+	oldGraphURL := sdk.GraphURL
+	sdk.GraphURL = ts.URL + "/"
+	t.Cleanup(func() {
+		sdk.GraphURL = oldGraphURL
+		ts.Close()
+	})
+	return sdk.CreateClient(&sdk.Config{Root: "/drive/root", AccessToken: "token"})
+}
+
+// startTestGraphServer returns a client talking to a test server which
+// responds with the JSON responses keyed by URL path.
+func startTestGraphServer(t *testing.T, responses map[string]string) *sdk.Client {
+	return newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+}
+
+func TestParseCommandFlags(t *testing.T) {
+	var dryRun, yes bool
+	newFlags := func() *flag.FlagSet {
+		dryRun, yes = false, false
+		f := flag.NewFlagSet("rm", flag.ContinueOnError)
+		f.BoolVar(&dryRun, "dry-run", false, "")
+		f.BoolVar(&yes, "yes", false, "")
+		return f
+	}
+
+	args := parseCommandFlags(newFlags(), []string{"/a", "--dry-run", "/b", "--yes"})
+	checkTestString(t, "/a|/b", strings.Join(args, "|"))
+	checkTestBool(t, true, dryRun)
+	checkTestBool(t, true, yes)
+
+	args = parseCommandFlags(newFlags(), []string{"--yes", "/a", "--", "-b", "--dry-run"})
+	checkTestString(t, "/a|-b|--dry-run", strings.Join(args, "|"))
+	checkTestBool(t, false, dryRun)
+	checkTestBool(t, true, yes)
+
+	args = parseCommandFlags(newFlags(), []string{"--", "--yes"})
+	checkTestInt(t, 1, len(args))
+	checkTestString(t, "--yes", args[0])
+	checkTestBool(t, false, yes)
+}
@@ -24,7 +24,7 @@ func (r *OutputRenderer) initProgressBar(maxBytes int64, desc string) {
 		progressbar.OptionShowBytes(true),
 		progressbar.OptionSetWidth(20),
 		progressbar.OptionShowCount(),
-		progressbar.OptionOnCompletion(func() { fmt.Printf("\n") }),
+		progressbar.OptionOnCompletion(func() { fmt.Fprintln(os.Stderr) }),
 		progressbar.OptionSpinnerType(14),
 		progressbar.OptionSetRenderBlankState(true),
 		progressbar.OptionThrottle(50*time.Millisecond),
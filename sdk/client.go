@@ -17,16 +17,48 @@ var (
 	GraphURL = "https://graph.microsoft.com/v1.0/"
 )
 
+var ErrNotFound = errors.New("path not found")
+
+// ConflictBehavior defines what happens if an uploaded file or a created
+// folder already exists.
+type ConflictBehavior string
+
+const (
+	// Fail with a ConflictError
+	ConflictBehaviorFail ConflictBehavior = "fail"
+	// Overwrite the existing item
+	ConflictBehaviorReplace ConflictBehavior = "replace"
+	// Let OneDrive choose a new, unique name
+	ConflictBehaviorRename ConflictBehavior = "rename"
+)
+
+// ConflictError is returned if an item already exists and the conflict
+// behavior is ConflictBehaviorFail.
+type ConflictError struct {
+	Path string
+}
+
+func (e *ConflictError) Error() string {
+	return "item already exists: " + e.Path
+}
+
 type transferProgress func(int64)
 
 type Client struct {
 	Config                  *Config
 	Verbose                 bool
 	UploadSessionRangeSize  int
+	DownloadConnections     int
+	ExistingFiles           ExistingFilePolicy
+	ConflictBehavior        ConflictBehavior
 	UseTransferSignals      bool
+	VerifyTransfers         bool
+	DeleteCorruptFiles      bool
 	ChannelTransferStart    chan fs.FileInfo
 	ChannelTransferProgress chan int64
 	ChannelTransferFinish   chan bool
+	loginState              string
+	rootPaths               []string
 }
 
 type HTTPRequestParams map[string]string
@@ -35,6 +67,7 @@ func CreateClient(conf *Config) *Client {
 	client := &Client{
 		Config:                 conf,
 		UploadSessionRangeSize: 320 * 30,
+		DownloadConnections:    1,
 		Verbose:                false,
 		UseTransferSignals:     false,
 	}
@@ -154,6 +187,13 @@ func (client *Client) httpPostJSON(uri string, o interface{}) (int, []byte, erro
 }
 
 func (client *Client) httpRequest(method, uri string, requestHeaders, params HTTPRequestParams, payload []byte, progress transferProgress) (int, []byte, error) {
+	status, _, body, err := client.httpRequestWithHeaders(method, uri, requestHeaders, params, payload, progress)
+	return status, body, err
+}
+
+// httpRequestWithHeaders is like httpRequest, but additionally returns the
+// response headers.
+func (client *Client) httpRequestWithHeaders(method, uri string, requestHeaders, params HTTPRequestParams, payload []byte, progress transferProgress) (int, http.Header, []byte, error) {
 	httpClient := &http.Client{}
 	uri = client.buildURI(uri, params)
 	total := int64(0)
@@ -168,7 +208,7 @@ func (client *Client) httpRequest(method, uri string, requestHeaders, params HTT
 	}
 	req, err := http.NewRequest(method, uri, reader)
 	if err != nil {
-		return -1, nil, err
+		return -1, nil, nil, err
 	}
 	req.ContentLength = int64(reader.Len())
 	for name, value := range requestHeaders {
@@ -176,14 +216,14 @@ func (client *Client) httpRequest(method, uri string, requestHeaders, params HTT
 	}
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return -1, nil, err
+		return -1, nil, nil, err
 	}
 	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return -1, nil, err
+		return -1, nil, nil, err
 	}
-	return resp.StatusCode, body, nil
+	return resp.StatusCode, resp.Header, body, nil
 }
 
 func (client *Client) handleResponseError(status int, data []byte) error {
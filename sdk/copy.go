@@ -0,0 +1,192 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"time"
+)
+
+var (
+	// Interval for polling the status of asynchronous copy jobs
+	CopyPollInterval = 1 * time.Second
+	// Maximum time to wait for an asynchronous copy job to complete
+	CopyTimeout = 6 * time.Hour
+)
+
+type CopyRequest struct {
+	ParentReference ItemReference `json:"parentReference"`
+	Name            string        `json:"name"`
+}
+
+// CopyStatus is the status of an asynchronous copy job as returned by the
+// monitor URL.
+type CopyStatus struct {
+	Status             string    `json:"status"`
+	PercentageComplete float64   `json:"percentageComplete"`
+	ResourceID         string    `json:"resourceId"`
+	Error              ErrorType `json:"error"`
+}
+
+var (
+	ErrCopyFailed  = errors.New("copy failed")
+	ErrCopyTimeout = errors.New("copy job did not complete in time (it may still be running on the server)")
+)
+
+// Copy copies the remote item at src to the path dst on the server side,
+// which works for files and folders. If driveID is set, dst is a path in
+// the drive with this ID, allowing to copy items across drives. The parent
+// folder of dst must exist.
+//
+// OneDrive performs the copy asynchronously. Copy waits until the job is
+// completed, but at most CopyTimeout, and signals the progress as the number
+// of bytes copied, which is estimated from the reported percentage.
+func (client *Client) Copy(src, dst, driveID string) error {
+	if (len(src) > 0 && src[0] == '.') || (len(dst) > 0 && dst[0] == '.') {
+		return errors.New("invalid path (should start with /)")
+	}
+	src = path.Clean("/" + src)
+	dst = path.Clean("/" + dst)
+	if dst == "/" {
+		return errors.New("please specify the target path including the name")
+	}
+	client.signalTransferStart(nil)
+	info, err := client.Info(src)
+	if err != nil {
+		return err
+	}
+	parent, err := client.copyTargetFolder(path.Dir(dst), driveID)
+	if err != nil {
+		return err
+	}
+	req := &CopyRequest{
+		ParentReference: ItemReference{
+			DriveID: driveID,
+			ID:      parent.ID,
+		},
+		Name: client.SanitizeFileName(path.Base(dst)),
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	requestHeaders := make(HTTPRequestParams)
+	requestHeaders["Content-Type"] = "application/json"
+	requestHeaders["Authorization"] = "Bearer " + client.Config.AccessToken
+	url := GraphURL + "me/drive/items/" + info.ID + "/copy"
+	if client.ConflictBehavior != "" {
+		params := make(HTTPRequestParams)
+		params["@microsoft.graph.conflictBehavior"] = string(client.ConflictBehavior)
+		url = client.buildURI(url, params)
+	}
+	status, headers, data, err := client.httpRequestWithHeaders("POST", url, requestHeaders, nil, payload, nil)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusConflict {
+		return &ConflictError{Path: dst}
+	}
+	if status != http.StatusAccepted {
+		return client.handleResponseError(status, data)
+	}
+	monitorURL := headers.Get("Location")
+	if monitorURL == "" {
+		return errors.New("no monitor URL returned for copy job")
+	}
+	client.signalTransferStart(&DownloadFileStat{
+		FileName:     info.Name,
+		SizeBytes:    info.SizeBytes,
+		LastModified: info.FileSystemInfo.LastModified,
+	})
+	defer client.signalTransferFinish()
+	var lastProgress int64 = -1
+	deadline := time.Now().Add(CopyTimeout)
+	for {
+		copyStatus, err := client.getCopyStatus(monitorURL)
+		if err != nil {
+			return err
+		}
+		progress := int64(copyStatus.PercentageComplete / 100 * float64(info.SizeBytes))
+		if progress != lastProgress {
+			client.signalTransferProgress(progress)
+			lastProgress = progress
+		}
+		switch copyStatus.Status {
+		case "completed":
+			return nil
+		case "failed", "deleteFailed":
+			if copyStatus.Error.Message != "" {
+				return errors.New(ErrCopyFailed.Error() + ": " + copyStatus.Error.Message)
+			}
+			return ErrCopyFailed
+		case "notStarted", "inProgress", "updating", "waiting", "deletePending":
+		default:
+			return errors.New("unknown copy job status: " + copyStatus.Status)
+		}
+		if time.Now().After(deadline) {
+			return ErrCopyTimeout
+		}
+		time.Sleep(CopyPollInterval)
+	}
+}
+
+// copyTargetFolder returns the folder at p, either in the client's root or,
+// if driveID is set, in the given drive.
+func (client *Client) copyTargetFolder(p, driveID string) (*DriveItem, error) {
+	if driveID == "" {
+		return client.Info(p)
+	}
+	url := GraphURL + "drives/" + driveID + "/root"
+	if p != "/" {
+		url += ":" + p
+	}
+	status, data, err := client.httpGet(url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		return nil, client.handleResponseError(status, data)
+	}
+	var item DriveItem
+	if err := UnmarshalJSON(&item, data); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// getCopyStatus queries the monitor URL of a copy job. The URL is
+// pre-authenticated, so no access token must be sent. Once the job is
+// completed, the monitor URL may redirect to the new item, which is not
+// followed.
+func (client *Client) getCopyStatus(monitorURL string) (*CopyStatus, error) {
+	httpClient := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := httpClient.Get(monitorURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusSeeOther {
+		return &CopyStatus{Status: "completed", PercentageComplete: 100}, nil
+	}
+	if !IsHTTPStatusOK(resp.StatusCode) {
+		return nil, client.handleResponseError(resp.StatusCode, data)
+	}
+	var copyStatus CopyStatus
+	if err := UnmarshalJSON(&copyStatus, data); err != nil {
+		return nil, err
+	}
+	return &copyStatus, nil
+}
@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyPollsMonitor(t *testing.T) {
+	var copied CopyRequest
+	var monitorAuth []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		switch {
+		case r.URL.Path == "/monitor":
+			monitorAuth = append(monitorAuth, r.Header.Get("Authorization"))
+			if len(monitorAuth) == 1 {
+				w.WriteHeader(http.StatusAccepted)
+				w.Write([]byte(`{"status":"inProgress","percentageComplete":50}`))
+				return
+			}
+			w.Header().Set("Location", GraphURL+"me/drive/items/new-id")
+			w.WriteHeader(http.StatusSeeOther)
+		case r.Method == "POST" && r.URL.Path == "/me/drive/items/src-id/copy":
+			json.Unmarshal(data, &copied)
+			w.Header().Set("Location", GraphURL+"monitor")
+			w.WriteHeader(http.StatusAccepted)
+		case r.URL.Path == "/drives/other-drive/root:/target":
+			w.Write([]byte(`{"id":"target-id","name":"target","folder":{"childCount":0}}`))
+		case r.URL.Path == "/me/drive/root:/file.bin":
+			w.Write([]byte(`{"id":"src-id","name":"file.bin","size":1000,"file":{"mimeType":"application/octet-stream"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	oldInterval := CopyPollInterval
+	CopyPollInterval = 0
+	defer func() { CopyPollInterval = oldInterval }()
+	client.UseTransferSignals = true
+	var progress []int64
+	var started fs.FileInfo
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case info := <-client.ChannelTransferStart:
+				if info != nil {
+					started = info
+				}
+			case b := <-client.ChannelTransferProgress:
+				progress = append(progress, b)
+			case <-client.ChannelTransferFinish:
+				done <- true
+				return
+			}
+		}
+	}()
+
+	err := client.Copy("/file.bin", "/target/copy.bin", "other-drive")
+	checkTestBool(t, true, err == nil)
+	<-done
+	checkTestString(t, "other-drive", copied.ParentReference.DriveID)
+	checkTestString(t, "target-id", copied.ParentReference.ID)
+	checkTestString(t, "copy.bin", copied.Name)
+	checkTestString(t, "file.bin", started.Name())
+	checkTestInt(t, 2, len(monitorAuth))
+	checkTestString(t, "", monitorAuth[0])
+	checkTestInt(t, 2, len(progress))
+	checkTestInt(t, 500, int(progress[0]))
+	checkTestInt(t, 1000, int(progress[1]))
+}
+
+func TestCopyMonitorErrors(t *testing.T) {
+	monitorStatus := ""
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		switch {
+		case r.URL.Path == "/monitor":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"status":"` + monitorStatus + `"}`))
+		case r.Method == "POST":
+			w.Header().Set("Location", GraphURL+"monitor")
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Write([]byte(`{"id":"id","name":"file.bin","size":1000,"file":{"mimeType":"application/octet-stream"}}`))
+		}
+	}))
+	oldInterval, oldTimeout := CopyPollInterval, CopyTimeout
+	CopyPollInterval = time.Millisecond
+	defer func() { CopyPollInterval, CopyTimeout = oldInterval, oldTimeout }()
+
+	monitorStatus = "somethingNew"
+	err := client.Copy("/file.bin", "/target/copy.bin", "")
+	checkTestBool(t, true, err != nil)
+	checkTestBool(t, true, strings.Contains(err.Error(), "somethingNew"))
+
+	monitorStatus = "inProgress"
+	CopyTimeout = 10 * time.Millisecond
+	err = client.Copy("/file.bin", "/target/copy.bin", "")
+	checkTestBool(t, true, err == ErrCopyTimeout)
+}
@@ -12,9 +12,11 @@ type FolderProperties struct {
 type CreateFolderRequest struct {
 	Name             string           `json:"name"`
 	Folder           FolderProperties `json:"folder"`
-	ConflictBehavior string           `json:"@microsoft.graph.conflictBehavior"`
+	ConflictBehavior ConflictBehavior `json:"@microsoft.graph.conflictBehavior"`
 }
 
+// CreateDir creates a remote folder. Unless the client's ConflictBehavior is
+// set, an existing folder is not considered an error.
 func (client *Client) CreateDir(path string) error {
 	if len(path) > 0 && path[0] == '.' {
 		return errors.New("invalid path (should start with /)")
@@ -27,10 +29,14 @@ func (client *Client) CreateDir(path string) error {
 		parentPathParts := pathParts[:len(pathParts)-1]
 		parentPath = "/" + strings.Join(parentPathParts, "/")
 	}
+	conflictBehavior := client.ConflictBehavior
+	if conflictBehavior == "" {
+		conflictBehavior = ConflictBehaviorFail
+	}
 	req := &CreateFolderRequest{
 		Name:             strings.TrimSpace(newFolder),
 		Folder:           FolderProperties{},
-		ConflictBehavior: "fail",
+		ConflictBehavior: conflictBehavior,
 	}
 	url := GraphURL + "me" + client.Config.Root + ":" + parentPath + ":/children"
 	if parentPath == "/" {
@@ -41,10 +47,13 @@ func (client *Client) CreateDir(path string) error {
 		return err
 	}
 	if status == http.StatusConflict {
+		if client.ConflictBehavior == ConflictBehaviorFail {
+			return &ConflictError{Path: "/" + path}
+		}
 		// Already exists
 		return nil
 	}
-	if status != http.StatusCreated {
+	if !IsHTTPStatusOK(status) {
 		return client.handleResponseError(status, data)
 	}
 	return nil
@@ -6,6 +6,7 @@ import (
 	"strings"
 )
 
+// Delete moves the remote item to the recycle bin.
 func (client *Client) Delete(path string) error {
 	if len(path) > 0 && path[0] == '.' {
 		return errors.New("invalid path (should start with /)")
@@ -20,10 +21,31 @@ func (client *Client) Delete(path string) error {
 		return err
 	}
 	if status == http.StatusNotFound {
-		return errors.New("path not found")
+		return ErrNotFound
 	}
 	if status != http.StatusNoContent {
 		return client.handleResponseError(status, data)
 	}
 	return nil
 }
+
+// DeletePermanently deletes the remote item without moving it to the recycle
+// bin. This is only supported for OneDrive for Business and SharePoint.
+func (client *Client) DeletePermanently(path string) error {
+	info, err := client.Info(path)
+	if err != nil {
+		return err
+	}
+	url := GraphURL + "me/drive/items/" + info.ID + "/permanentDelete"
+	status, data, err := client.httpPostJSON(url, &EmptyStruct{})
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if !IsHTTPStatusOK(status) {
+		return client.handleResponseError(status, data)
+	}
+	return nil
+}
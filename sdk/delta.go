@@ -0,0 +1,57 @@
+package sdk
+
+import (
+	"errors"
+	"net/http"
+)
+
+type DeltaResponse struct {
+	Items     []DriveItem `json:"value"`
+	NextLink  string      `json:"@odata.nextLink"`
+	DeltaLink string      `json:"@odata.deltaLink"`
+}
+
+// ErrDeltaTokenExpired is returned by Delta if the server doesn't accept the
+// delta link anymore. The caller has to start over with an empty link.
+var ErrDeltaTokenExpired = errors.New("delta token expired, full resync required")
+
+// Delta returns all items changed since the delta link was issued. An empty
+// link enumerates the whole drive root. The returned link is used to fetch
+// the next set of changes.
+func (client *Client) Delta(deltaLink string) ([]*DriveItem, string, error) {
+	url := deltaLink
+	if url == "" {
+		url = GraphURL + "me" + client.Config.Root + "/delta"
+	}
+	var result []*DriveItem
+	for url != "" {
+		status, data, err := client.httpGet(url, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		if status == http.StatusGone {
+			return nil, "", ErrDeltaTokenExpired
+		}
+		if status != http.StatusOK {
+			return nil, "", client.handleResponseError(status, data)
+		}
+		var resp DeltaResponse
+		if err := UnmarshalJSON(&resp, data); err != nil {
+			return nil, "", err
+		}
+		for i := range resp.Items {
+			driveItem := &resp.Items[i]
+			if driveItem.File.MimeType != "" {
+				driveItem.Type = DriveItemTypeFile
+			} else {
+				driveItem.Type = DriveItemTypeFolder
+			}
+			result = append(result, driveItem)
+		}
+		if resp.DeltaLink != "" {
+			return result, resp.DeltaLink, nil
+		}
+		url = resp.NextLink
+	}
+	return nil, "", errors.New("received neither next link nor delta link")
+}
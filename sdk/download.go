@@ -1,19 +1,42 @@
 package sdk
 
 import (
+	"context"
 	"errors"
 	"io"
 	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+var (
+	// Files smaller than this are always downloaded using one connection
+	ParallelDownloadMinSize int = 10 * 1024 * 1024 // 10 MB
+)
+
+// ExistingFilePolicy defines how downloads handle existing local files.
+type ExistingFilePolicy int
+
+const (
+	ExistingFileOverwrite ExistingFilePolicy = 0
+	// Fail with ErrFileExists
+	ExistingFileNoClobber ExistingFilePolicy = 1
+	// Rename the existing file by appending "~", or ".~1~", ".~2~" etc. if
+	// that backup exists already
+	ExistingFileBackup ExistingFilePolicy = 2
+)
+
+var ErrFileExists = errors.New("local file already exists")
+
 type DownloadFileStat struct {
-	FileName  string
-	SizeBytes int64
+	FileName     string
+	SizeBytes    int64
+	LastModified time.Time
 }
 
 func (s *DownloadFileStat) Name() string {
@@ -29,7 +52,7 @@ func (s *DownloadFileStat) Mode() fs.FileMode {
 }
 
 func (s *DownloadFileStat) ModTime() time.Time {
-	return time.Now()
+	return s.LastModified
 }
 
 func (s *DownloadFileStat) IsDir() bool {
@@ -40,7 +63,16 @@ func (s *DownloadFileStat) Sys() any {
 	return nil
 }
 
-func (client *Client) Download(sourceFilePath, targetFolder string) error {
+// Download downloads the remote file to target. If target is an existing
+// directory or ends with a path separator, the file is stored in it using
+// the remote file's name, otherwise target is the path of the local file.
+//
+// Data is written to a ".part" file first, which is synced to disk and
+// renamed once the download is complete, so readers never see a partial
+// file. If a previous download has been interrupted, it is resumed from the
+// length of the existing ".part" file, unless the remote file's eTag has
+// changed. The local file's modification time is set to the remote one.
+func (client *Client) Download(sourceFilePath, target string) error {
 	if len(sourceFilePath) > 0 && sourceFilePath[0] == '.' {
 		return errors.New("invalid source path (should start with /)")
 	}
@@ -52,8 +84,14 @@ func (client *Client) Download(sourceFilePath, targetFolder string) error {
 	if !strings.HasPrefix(sourceFilePath, "/") {
 		sourceFilePath = "/" + sourceFilePath
 	}
-	if !strings.HasSuffix(targetFolder, "/") {
-		targetFolder += "/"
+	targetFile := target
+	if stat, err := os.Stat(target); (err == nil && stat.IsDir()) || strings.HasSuffix(target, "/") || strings.HasSuffix(target, string(filepath.Separator)) {
+		targetFile = filepath.Join(target, fileName)
+	}
+	if client.ExistingFiles == ExistingFileNoClobber {
+		if _, err := os.Stat(targetFile); err == nil {
+			return ErrFileExists
+		}
 	}
 	// Get file info
 	client.signalTransferStart(nil)
@@ -61,52 +99,341 @@ func (client *Client) Download(sourceFilePath, targetFolder string) error {
 	if err != nil {
 		return err
 	}
+	partFile := targetFile + ".part"
+	offset := client.resumeOffset(partFile, info.ETag)
+	if offset > info.SizeBytes {
+		offset = 0
+	}
+	if err := os.WriteFile(partFile+".etag", []byte(info.ETag), 0600); err != nil {
+		return err
+	}
 	// Start download
-	url := GraphURL + "me" + client.Config.Root + ":" + sourceFilePath + ":/content"
-	req, err := http.NewRequest("GET", url, nil)
+	fileStat := &DownloadFileStat{
+		FileName:     info.Name,
+		SizeBytes:    info.SizeBytes,
+		LastModified: info.FileSystemInfo.LastModified,
+	}
+	var hasher *transferHasher
+	if client.VerifyTransfers {
+		hasher = newTransferHasher()
+	}
+	if client.DownloadConnections > 1 && offset == 0 && info.SizeBytes >= int64(ParallelDownloadMinSize) {
+		err = client.downloadParallel(sourceFilePath, partFile, fileStat, hasher)
+	} else {
+		err = client.downloadSequential(sourceFilePath, partFile, fileStat, offset, hasher)
+	}
 	if err != nil {
 		return err
 	}
-	req.Header.Add("Authorization", "Bearer "+client.Config.AccessToken)
-	httpClient := &http.Client{}
-	resp, err := httpClient.Do(req)
+	if hasher != nil {
+		err = hasher.Verify(sourceFilePath, &info.File.Hashes)
+		if _, ok := err.(*IntegrityError); ok {
+			// The corrupt part must never be resumed, so the next run
+			// downloads the file again
+			os.Remove(partFile + ".etag")
+			if client.DeleteCorruptFiles {
+				os.Remove(partFile)
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := client.replaceFile(partFile, targetFile); err != nil {
+		return err
+	}
+	os.Remove(partFile + ".etag")
+	if modTime := info.FileSystemInfo.LastModified; !modTime.IsZero() {
+		return os.Chtimes(targetFile, modTime, modTime)
+	}
+	return nil
+}
+
+// replaceFile moves the downloaded file into place, handling an existing
+// target file according to the client's ExistingFiles policy.
+func (client *Client) replaceFile(partFile, targetFile string) error {
+	switch client.ExistingFiles {
+	case ExistingFileNoClobber:
+		return renameNoClobber(partFile, targetFile)
+	case ExistingFileBackup:
+		if _, err := os.Lstat(targetFile); err == nil {
+			if err := backupFile(targetFile); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(partFile, targetFile)
+}
+
+// renameNoClobber renames src to dst unless dst exists. Other than checking
+// for dst before renaming, a file created concurrently is never replaced.
+func renameNoClobber(src, dst string) error {
+	err := os.Link(src, dst)
+	if err == nil {
+		return os.Remove(src)
+	}
+	if os.IsExist(err) {
+		return ErrFileExists
+	}
+	// Hard links are not supported, reserve the name instead
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if os.IsExist(err) {
+		return ErrFileExists
+	}
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return errors.New("file not found")
+	f.Close()
+	return os.Rename(src, dst)
+}
+
+// backupFile keeps the content of file under the first unused backup name.
+// The file itself stays in place until it is replaced.
+func backupFile(file string) error {
+	for i := 0; ; i++ {
+		backup := file + "~"
+		if i > 0 {
+			backup = file + ".~" + strconv.Itoa(i) + "~"
+		}
+		err := os.Link(file, backup)
+		if err == nil {
+			return nil
+		}
+		if os.IsExist(err) {
+			continue
+		}
+		// Hard links are not supported
+		if _, statErr := os.Lstat(backup); statErr == nil {
+			continue
+		}
+		return os.Rename(file, backup)
 	}
-	if resp.StatusCode != http.StatusOK {
-		data, err := io.ReadAll(resp.Body)
+}
+
+func (client *Client) downloadSequential(sourceFilePath, partFile string, fileStat *DownloadFileStat, offset int64, hasher *transferHasher) error {
+	var resp *http.Response
+	if offset < fileStat.SizeBytes {
+		var err error
+		resp, err = client.requestContent(context.Background(), sourceFilePath, offset, 0)
 		if err != nil {
 			return err
 		}
-		return client.handleResponseError(resp.StatusCode, data)
-	}
-	fileStat := &DownloadFileStat{
-		FileName:  info.Name,
-		SizeBytes: info.SizeBytes,
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			// Range not supported, start over
+			offset = 0
+		}
 	}
 	client.signalTransferStart(fileStat)
-	out, err := os.Create(targetFolder + fileName)
+	defer client.signalTransferFinish()
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_CREATE | os.O_RDWR
+	}
+	out, err := os.OpenFile(partFile, flags, 0644)
 	if err != nil {
-		client.signalTransferFinish()
 		return err
 	}
 	defer out.Close()
-	total := int64(0)
-	reader := &ProgressReader{
+	if hasher != nil {
+		// Include the previously downloaded data
+		if _, err := io.Copy(hasher, io.LimitReader(out, offset)); err != nil {
+			return err
+		}
+	}
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if resp == nil {
+		return out.Sync()
+	}
+	total := offset
+	var reader io.Reader = &ProgressReader{
 		Reader: resp.Body,
 		OnReadProgress: func(r int64) {
 			total += r
 			client.signalTransferProgress(total)
 		},
 	}
-	_, err = io.Copy(out, reader)
+	if hasher != nil {
+		reader = io.TeeReader(reader, hasher)
+	}
+	if _, err = io.Copy(out, reader); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// downloadParallel splits the file into one range per connection and fetches
+// the ranges concurrently, writing each one to its position in the
+// preallocated part file. The first failing range cancels the others.
+func (client *Client) downloadParallel(sourceFilePath, partFile string, fileStat *DownloadFileStat, hasher *transferHasher) error {
+	client.signalTransferStart(fileStat)
+	out, err := os.OpenFile(partFile, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		client.signalTransferFinish()
+		return err
+	}
+	defer out.Close()
+	if err := out.Truncate(fileStat.SizeBytes); err != nil {
+		client.signalTransferFinish()
+		return err
+	}
+	rangeSize := fileStat.SizeBytes / int64(client.DownloadConnections)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var total int64 = 0
+	var firstErr error
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < client.DownloadConnections; i++ {
+		offset := int64(i) * rangeSize
+		length := rangeSize
+		if i == client.DownloadConnections-1 {
+			length = fileStat.SizeBytes - offset
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.downloadRange(ctx, sourceFilePath, out, offset, length, func(r int64) {
+				mutex.Lock()
+				total += r
+				client.signalTransferProgress(total)
+				mutex.Unlock()
+			})
+			if err != nil {
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
 	client.signalTransferFinish()
+	if firstErr != nil {
+		// The preallocated part file must not be resumed
+		os.Remove(partFile + ".etag")
+		return firstErr
+	}
+	if hasher != nil {
+		if _, err := out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := io.Copy(hasher, out); err != nil {
+			return err
+		}
+	}
+	return out.Sync()
+}
+
+func (client *Client) downloadRange(ctx context.Context, sourceFilePath string, out io.WriterAt, offset, length int64, progress transferProgress) error {
+	resp, err := client.requestContent(ctx, sourceFilePath, offset, length)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return errors.New("server does not support range requests")
+	}
+	reader := &ProgressReader{
+		Reader:         io.LimitReader(resp.Body, length),
+		OnReadProgress: progress,
+	}
+	n, err := io.Copy(io.NewOffsetWriter(out, offset), reader)
 	if err != nil {
 		return err
 	}
+	if n != length {
+		return errors.New("received incomplete range")
+	}
 	return nil
 }
+
+// OpenContent returns a reader for the content of a remote file. If offset
+// or length are greater than zero, only the given range is returned. The
+// caller has to close the reader.
+func (client *Client) OpenContent(sourceFilePath string, offset, length int64) (io.ReadCloser, error) {
+	if len(sourceFilePath) > 0 && sourceFilePath[0] == '.' {
+		return nil, errors.New("invalid source path (should start with /)")
+	}
+	if offset < 0 || length < 0 {
+		return nil, errors.New("invalid range")
+	}
+	sourceFilePath = strings.TrimSuffix(sourceFilePath, "/")
+	if !strings.HasPrefix(sourceFilePath, "/") {
+		sourceFilePath = "/" + sourceFilePath
+	}
+	resp, err := client.requestContent(context.Background(), sourceFilePath, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK && (offset > 0 || length > 0) {
+		// Range not supported, skip unwanted data
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if length > 0 {
+			return struct {
+				io.Reader
+				io.Closer
+			}{io.LimitReader(resp.Body, length), resp.Body}, nil
+		}
+	}
+	return resp.Body, nil
+}
+
+// resumeOffset returns the number of bytes which can be reused from an
+// interrupted download. The data is only reused if it belongs to the same
+// version of the remote file.
+func (client *Client) resumeOffset(partFile, eTag string) int64 {
+	stat, err := os.Stat(partFile)
+	if err != nil {
+		return 0
+	}
+	previousETag, err := os.ReadFile(partFile + ".etag")
+	if err != nil || eTag == "" || string(previousETag) != eTag {
+		return 0
+	}
+	return stat.Size()
+}
+
+// requestContent requests the content of a remote file. If offset or length
+// are greater than zero, only the requested range is fetched. The caller has
+// to close the response body and must check the status code, which is 206 if
+// the range has been applied or 200 if the whole content is returned.
+func (client *Client) requestContent(ctx context.Context, sourceFilePath string, offset, length int64) (*http.Response, error) {
+	url := GraphURL + "me" + client.Config.Root + ":" + sourceFilePath + ":/content"
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+client.Config.AccessToken)
+	if length > 0 {
+		req.Header.Add("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	} else if offset > 0 {
+		req.Header.Add("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, client.handleResponseError(resp.StatusCode, data)
+	}
+	return resp, nil
+}
@@ -0,0 +1,303 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testFileServer struct {
+	Content []byte
+	ETag    string
+	ModTime time.Time
+	SHA256  string
+	// Requests for ranges starting at this offset fail if set
+	FailOffset string
+	Ranges     []string
+	mutex      sync.Mutex
+}
+
+func (s *testFileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, ":/content") {
+		rangeHeader := r.Header.Get("Range")
+		s.mutex.Lock()
+		s.Ranges = append(s.Ranges, rangeHeader)
+		s.mutex.Unlock()
+		if s.FailOffset != "" && strings.HasPrefix(rangeHeader, "bytes="+s.FailOffset+"-") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(s.Content))
+		return
+	}
+	fileSystemInfo := ""
+	if !s.ModTime.IsZero() {
+		fileSystemInfo = `,"fileSystemInfo":{"lastModifiedDateTime":"` + s.ModTime.Format(time.RFC3339) + `"}`
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"name":"file.bin","size":` + strconv.Itoa(len(s.Content)) + `,"eTag":"` + s.ETag + `","file":{"mimeType":"application/octet-stream","hashes":{"sha256Hash":"` + s.SHA256 + `"}}` + fileSystemInfo + `}`))
+}
+
+func TestDownloadResume(t *testing.T) {
+	server := &testFileServer{Content: make([]byte, 100000), ETag: "v1"}
+	rand.Read(server.Content)
+	client := newTestClient(t, server)
+	dir := t.TempDir()
+	partFile := filepath.Join(dir, "file.bin.part")
+	os.WriteFile(partFile, server.Content[:40000], 0600)
+	os.WriteFile(partFile+".etag", []byte("v1"), 0600)
+
+	err := client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(server.Ranges))
+	checkTestString(t, "bytes=40000-", server.Ranges[0])
+	data, _ := os.ReadFile(filepath.Join(dir, "file.bin"))
+	checkTestBool(t, true, bytes.Equal(server.Content, data))
+	_, err = os.Stat(partFile)
+	checkTestBool(t, true, os.IsNotExist(err))
+	_, err = os.Stat(partFile + ".etag")
+	checkTestBool(t, true, os.IsNotExist(err))
+}
+
+func TestDownloadRestartOnChangedETag(t *testing.T) {
+	server := &testFileServer{Content: make([]byte, 1000), ETag: "v2"}
+	rand.Read(server.Content)
+	client := newTestClient(t, server)
+	dir := t.TempDir()
+	partFile := filepath.Join(dir, "file.bin.part")
+	os.WriteFile(partFile, make([]byte, 500), 0600)
+	os.WriteFile(partFile+".etag", []byte("v1"), 0600)
+
+	err := client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "", server.Ranges[0])
+	data, _ := os.ReadFile(filepath.Join(dir, "file.bin"))
+	checkTestBool(t, true, bytes.Equal(server.Content, data))
+}
+
+func TestDownloadParallel(t *testing.T) {
+	server := &testFileServer{Content: make([]byte, 100003), ETag: "v1"}
+	rand.Read(server.Content)
+	client := newTestClient(t, server)
+	client.DownloadConnections = 4
+	client.VerifyTransfers = true
+	oldMinSize := ParallelDownloadMinSize
+	ParallelDownloadMinSize = 1000
+	defer func() { ParallelDownloadMinSize = oldMinSize }()
+	dir := t.TempDir()
+
+	err := client.Download("/file.bin", dir)
+	// The test server doesn't provide hashes
+	checkTestBool(t, true, err == ErrNoHashAvailable)
+	checkTestInt(t, 4, len(server.Ranges))
+	sort.Strings(server.Ranges)
+	checkTestString(t, "bytes=0-24999", server.Ranges[0])
+	checkTestString(t, "bytes=75000-100002", server.Ranges[3])
+
+	client.VerifyTransfers = false
+	err = client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	data, _ := os.ReadFile(filepath.Join(dir, "file.bin"))
+	checkTestBool(t, true, bytes.Equal(server.Content, data))
+}
+
+func TestDownloadParallelVerified(t *testing.T) {
+	server := &testFileServer{Content: make([]byte, 100003), ETag: "v1"}
+	rand.Read(server.Content)
+	sum := sha256.Sum256(server.Content)
+	server.SHA256 = strings.ToUpper(hex.EncodeToString(sum[:]))
+	client := newTestClient(t, server)
+	client.DownloadConnections = 4
+	client.VerifyTransfers = true
+	oldMinSize := ParallelDownloadMinSize
+	ParallelDownloadMinSize = 1000
+	defer func() { ParallelDownloadMinSize = oldMinSize }()
+	dir := t.TempDir()
+
+	err := client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 4, len(server.Ranges))
+	data, _ := os.ReadFile(filepath.Join(dir, "file.bin"))
+	checkTestBool(t, true, bytes.Equal(server.Content, data))
+
+	// A corrupted download is detected
+	server.SHA256 = strings.Repeat("0", 64)
+	err = client.Download("/file.bin", filepath.Join(dir, "other.bin"))
+	_, ok := err.(*IntegrityError)
+	checkTestBool(t, true, ok)
+}
+
+func TestDownloadRetryAfterIntegrityError(t *testing.T) {
+	server := &testFileServer{Content: []byte("hello world"), ETag: "v1"}
+	sum := sha256.Sum256(server.Content)
+	server.SHA256 = hex.EncodeToString(sum[:])
+	client := newTestClient(t, server)
+	client.VerifyTransfers = true
+	dir := t.TempDir()
+	partFile := filepath.Join(dir, "file.bin.part")
+
+	// The server delivers corrupt content, the part file is kept
+	server.Content = []byte("hello w0rld")
+	_, ok := client.Download("/file.bin", dir).(*IntegrityError)
+	checkTestBool(t, true, ok)
+	_, err := os.Stat(partFile)
+	checkTestBool(t, true, err == nil)
+
+	// The retry downloads the whole file again instead of resuming
+	server.Content = []byte("hello world")
+	server.Ranges = nil
+	checkTestBool(t, true, client.Download("/file.bin", dir) == nil)
+	checkTestInt(t, 1, len(server.Ranges))
+	checkTestString(t, "", server.Ranges[0])
+	data, _ := os.ReadFile(filepath.Join(dir, "file.bin"))
+	checkTestString(t, "hello world", string(data))
+
+	// Without verification, the corrupt part isn't moved into place either
+	server.Content = []byte("hello w0rld")
+	_, ok = client.Download("/file.bin", filepath.Join(dir, "other.bin")).(*IntegrityError)
+	checkTestBool(t, true, ok)
+	server.Content = []byte("hello world")
+	client.VerifyTransfers = false
+	checkTestBool(t, true, client.Download("/file.bin", filepath.Join(dir, "other.bin")) == nil)
+	data, _ = os.ReadFile(filepath.Join(dir, "other.bin"))
+	checkTestString(t, "hello world", string(data))
+}
+
+func TestDownloadParallelRangeFails(t *testing.T) {
+	server := &testFileServer{Content: make([]byte, 100003), ETag: "v1", FailOffset: "25000"}
+	client := newTestClient(t, server)
+	client.DownloadConnections = 4
+	oldMinSize := ParallelDownloadMinSize
+	ParallelDownloadMinSize = 1000
+	defer func() { ParallelDownloadMinSize = oldMinSize }()
+	dir := t.TempDir()
+
+	err := client.Download("/file.bin", dir)
+	checkTestBool(t, true, err != nil)
+	_, statErr := os.Stat(filepath.Join(dir, "file.bin"))
+	checkTestBool(t, true, os.IsNotExist(statErr))
+	_, statErr = os.Stat(filepath.Join(dir, "file.bin.part.etag"))
+	checkTestBool(t, true, os.IsNotExist(statErr))
+}
+
+func TestOpenContentRange(t *testing.T) {
+	server := &testFileServer{Content: []byte("0123456789"), ETag: "v1"}
+	client := newTestClient(t, server)
+
+	reader, err := client.OpenContent("/file.bin", 2, 5)
+	checkTestBool(t, true, err == nil)
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	checkTestString(t, "23456", string(data))
+	checkTestString(t, "bytes=2-6", server.Ranges[0])
+
+	reader, err = client.OpenContent("file.bin", 0, 0)
+	checkTestBool(t, true, err == nil)
+	data, _ = io.ReadAll(reader)
+	reader.Close()
+	checkTestString(t, "0123456789", string(data))
+	checkTestString(t, "", server.Ranges[1])
+}
+
+func TestOpenContentNotFound(t *testing.T) {
+	client := newTestClient(t, http.NotFoundHandler())
+
+	_, err := client.OpenContent("/missing.bin", 0, 0)
+	checkTestBool(t, true, err == ErrNotFound)
+}
+
+func TestDownloadExplicitFileName(t *testing.T) {
+	server := &testFileServer{Content: []byte("new"), ETag: "v1"}
+	client := newTestClient(t, server)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "renamed.bin")
+
+	err := client.Download("/file.bin", target)
+	checkTestBool(t, true, err == nil)
+	data, _ := os.ReadFile(target)
+	checkTestString(t, "new", string(data))
+
+	// Trailing separator denotes a (new) directory
+	err = client.Download("/file.bin", filepath.Join(dir, "sub")+string(filepath.Separator))
+	checkTestBool(t, false, err == nil)
+}
+
+func TestDownloadExistingFile(t *testing.T) {
+	server := &testFileServer{Content: []byte("new"), ETag: "v1"}
+	client := newTestClient(t, server)
+	dir := t.TempDir()
+	target := filepath.Join(dir, "file.bin")
+	os.WriteFile(target, []byte("old"), 0600)
+
+	client.ExistingFiles = ExistingFileNoClobber
+	err := client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == ErrFileExists)
+	data, _ := os.ReadFile(target)
+	checkTestString(t, "old", string(data))
+
+	client.ExistingFiles = ExistingFileBackup
+	err = client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	data, _ = os.ReadFile(target)
+	checkTestString(t, "new", string(data))
+	data, _ = os.ReadFile(target + "~")
+	checkTestString(t, "old", string(data))
+
+	// Existing backups are kept
+	err = client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	data, _ = os.ReadFile(target + "~")
+	checkTestString(t, "old", string(data))
+	data, _ = os.ReadFile(target + ".~1~")
+	checkTestString(t, "new", string(data))
+}
+
+func TestReplaceFileNoClobber(t *testing.T) {
+	client := CreateClient(&Config{})
+	client.ExistingFiles = ExistingFileNoClobber
+	dir := t.TempDir()
+	partFile := filepath.Join(dir, "file.bin.part")
+	target := filepath.Join(dir, "file.bin")
+	os.WriteFile(partFile, []byte("new"), 0600)
+
+	// The target has been created after the download started
+	os.WriteFile(target, []byte("old"), 0600)
+	err := client.replaceFile(partFile, target)
+	checkTestBool(t, true, err == ErrFileExists)
+	data, _ := os.ReadFile(target)
+	checkTestString(t, "old", string(data))
+	_, err = os.Stat(partFile)
+	checkTestBool(t, true, err == nil)
+
+	os.Remove(target)
+	err = client.replaceFile(partFile, target)
+	checkTestBool(t, true, err == nil)
+	data, _ = os.ReadFile(target)
+	checkTestString(t, "new", string(data))
+	_, err = os.Stat(partFile)
+	checkTestBool(t, true, os.IsNotExist(err))
+}
+
+func TestDownloadPreservesModTime(t *testing.T) {
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	server := &testFileServer{Content: []byte("hello"), ETag: "v1", ModTime: modTime}
+	client := newTestClient(t, server)
+	dir := t.TempDir()
+
+	err := client.Download("/file.bin", dir)
+	checkTestBool(t, true, err == nil)
+	stat, err := os.Stat(filepath.Join(dir, "file.bin"))
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, stat.ModTime().Equal(modTime))
+}
@@ -0,0 +1,17 @@
+//go:build darwin
+// +build darwin
+
+package sdk
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fileCreationTime(info os.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Birthtimespec.Unix())
+	}
+	return info.ModTime()
+}
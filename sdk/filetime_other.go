@@ -0,0 +1,15 @@
+//go:build !windows && !darwin
+// +build !windows,!darwin
+
+package sdk
+
+import (
+	"os"
+	"time"
+)
+
+// The creation time is not available on all platforms, so the modification
+// time is used instead.
+func fileCreationTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
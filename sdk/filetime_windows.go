@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+package sdk
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+func fileCreationTime(info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.CreationTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
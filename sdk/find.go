@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"path"
+	"regexp"
+	"time"
+)
+
+// ItemFilter selects drive items by their properties. Zero values don't
+// restrict the selection. The size of a folder is the total size of its
+// contents.
+type ItemFilter struct {
+	// Pattern matched against the item name using path.Match
+	NameGlob string
+	// Regular expression matched against the item name
+	NameRegexp *regexp.Regexp
+	Type       DriveItemType
+	MinSize    int64
+	MaxSize    *int64
+	// Only items modified before or after these times are selected
+	ModifiedBefore time.Time
+	ModifiedAfter  time.Time
+}
+
+// Match reports whether the item is selected by the filter.
+func (f *ItemFilter) Match(item *DriveItem) bool {
+	if f.NameGlob != "" {
+		if ok, _ := path.Match(f.NameGlob, item.Name); !ok {
+			return false
+		}
+	}
+	if f.NameRegexp != nil && !f.NameRegexp.MatchString(item.Name) {
+		return false
+	}
+	if f.Type != 0 && item.Type != f.Type {
+		return false
+	}
+	if item.SizeBytes < f.MinSize {
+		return false
+	}
+	if f.MaxSize != nil && item.SizeBytes > *f.MaxSize {
+		return false
+	}
+	modified := item.FileSystemInfo.LastModified
+	if !f.ModifiedBefore.IsZero() && !modified.Before(f.ModifiedBefore) {
+		return false
+	}
+	if !f.ModifiedAfter.IsZero() && !modified.After(f.ModifiedAfter) {
+		return false
+	}
+	return true
+}
+
+// WalkFunc is called by Walk for each item with the item's path.
+type WalkFunc func(p string, item *DriveItem) error
+
+// Walk calls fn for all items below dir, folders before their contents. The
+// items of a folder are visited in the order returned by List. If fn returns
+// an error, walking stops and the error is returned.
+func (client *Client) Walk(dir string, fn WalkFunc) error {
+	items, err := client.List(dir)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		p := path.Join("/", dir, item.Name)
+		if err := fn(p, item); err != nil {
+			return err
+		}
+		if item.Type == DriveItemTypeFolder {
+			if err := client.Walk(p, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the paths and items below dir selected by the filter.
+func (client *Client) Find(dir string, filter *ItemFilter) ([]string, []*DriveItem, error) {
+	var paths []string
+	var items []*DriveItem
+	err := client.Walk(dir, func(p string, item *DriveItem) error {
+		if filter.Match(item) {
+			paths = append(paths, p)
+			items = append(items, item)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return paths, items, nil
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestItemFilterMatch(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	item := &DriveItem{
+		Name:           "backup-2026-09-01.tar.gz",
+		SizeBytes:      2 << 30,
+		Type:           DriveItemTypeFile,
+		FileSystemInfo: FileSystemInfo{LastModified: now.AddDate(0, 0, -48)},
+	}
+	var maxSize int64 = 1 << 30
+	checkTestBool(t, true, (&ItemFilter{}).Match(item))
+	checkTestBool(t, true, (&ItemFilter{NameGlob: "backup-*.tar.gz"}).Match(item))
+	checkTestBool(t, false, (&ItemFilter{NameGlob: "*.zip"}).Match(item))
+	checkTestBool(t, true, (&ItemFilter{NameRegexp: regexp.MustCompile(`^backup-\d{4}`)}).Match(item))
+	checkTestBool(t, false, (&ItemFilter{Type: DriveItemTypeFolder}).Match(item))
+	checkTestBool(t, true, (&ItemFilter{MinSize: 1 << 30}).Match(item))
+	checkTestBool(t, false, (&ItemFilter{MaxSize: &maxSize}).Match(item))
+	checkTestBool(t, true, (&ItemFilter{ModifiedBefore: now.AddDate(0, 0, -30)}).Match(item))
+	checkTestBool(t, false, (&ItemFilter{ModifiedAfter: now.AddDate(0, 0, -30)}).Match(item))
+
+	var zero int64 = 0
+	checkTestBool(t, true, (&ItemFilter{MaxSize: &zero}).Match(&DriveItem{Name: "empty.txt"}))
+}
+
+func TestFindPagedListing(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/me/drive/root:/backups:/children":
+			w.Write([]byte(`{"value":[{"name":"a.tar.gz","size":5,"file":{"mimeType":"application/gzip"}}],"@odata.nextLink":"` + GraphURL + `page2"}`))
+		case "/page2":
+			w.Write([]byte(`{"value":[{"name":"old","size":20,"folder":{"childCount":1}}]}`))
+		case "/me/drive/root:/backups/old:/children":
+			w.Write([]byte(`{"value":[{"name":"b.tar.gz","size":20,"file":{"mimeType":"application/gzip"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	paths, items, err := client.Find("/backups", &ItemFilter{NameGlob: "*.tar.gz"})
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/backups/a.tar.gz,/backups/old/b.tar.gz", strings.Join(paths, ","))
+	checkTestInt(t, 2, len(items))
+
+	paths, _, err = client.Find("/backups", &ItemFilter{Type: DriveItemTypeFile, MinSize: 10})
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/backups/old/b.tar.gz", strings.Join(paths, ","))
+
+	// Folders are matched by their aggregate size unless the type is given
+	paths, _, err = client.Find("/backups", &ItemFilter{MinSize: 10})
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/backups/old,/backups/old/b.tar.gz", strings.Join(paths, ","))
+}
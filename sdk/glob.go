@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"path"
+	"strings"
+)
+
+// Glob returns the paths of all remote items matching pattern, using the
+// syntax of path.Match. Wildcards may be used in any path component and are
+// matched against the folder listings. Components without wildcards are
+// taken as they are, so the returned paths may not exist.
+func (client *Client) Glob(pattern string) ([]string, error) {
+	pattern = path.Clean("/" + pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	matches := []string{"/"}
+	parts := strings.Split(strings.TrimPrefix(pattern, "/"), "/")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		isLast := i == len(parts)-1
+		var next []string
+		for _, dir := range matches {
+			if !hasGlobMeta(part) {
+				next = append(next, path.Join(dir, part))
+				continue
+			}
+			items, err := client.List(dir)
+			if err == ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if !isLast && item.Type != DriveItemTypeFolder {
+					continue
+				}
+				if ok, _ := path.Match(part, item.Name); ok {
+					next = append(next, path.Join(dir, item.Name))
+				}
+			}
+		}
+		matches = next
+	}
+	return matches, nil
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
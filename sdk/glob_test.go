@@ -0,0 +1,43 @@
+package sdk
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestGlob(t *testing.T) {
+	listings := map[string]string{
+		"/me/drive/root:/logs:/children":     `{"value":[{"name":"a.tmp","file":{"mimeType":"text/plain"}},{"name":"b.log","file":{"mimeType":"text/plain"}},{"name":"old","folder":{"childCount":1}}]}`,
+		"/me/drive/root/children":            `{"value":[{"name":"logs","folder":{"childCount":3}},{"name":"logs.tmp","file":{"mimeType":"text/plain"}}]}`,
+		"/me/drive/root:/logs/old:/children": `{"value":[{"name":"c.tmp","file":{"mimeType":"text/plain"}}]}`,
+	}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listing, ok := listings[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(listing))
+	}))
+
+	matches, err := client.Glob("/logs/*.tmp")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/logs/a.tmp", strings.Join(matches, ","))
+
+	matches, err = client.Glob("/log*/*.tmp")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/logs/a.tmp", strings.Join(matches, ","))
+
+	matches, err = client.Glob("/logs/*/*.tmp")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/logs/old/c.tmp", strings.Join(matches, ","))
+
+	matches, err = client.Glob("/logs/b.log")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/logs/b.log", strings.Join(matches, ","))
+
+	_, err = client.Glob("/logs/[")
+	checkTestBool(t, true, err != nil)
+}
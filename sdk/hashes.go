@@ -0,0 +1,162 @@
+package sdk
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+type HashAlgorithm string
+
+var ErrUnknownHashAlgorithm = errors.New("unknown hash algorithm")
+
+const (
+	HashAlgorithmSHA256   HashAlgorithm = "sha256"
+	HashAlgorithmSHA1     HashAlgorithm = "sha1"
+	HashAlgorithmQuickXor HashAlgorithm = "quickxor"
+)
+
+// Strongest returns the strongest hash provided by the remote item. Personal
+// accounts return SHA1 and SHA256 hashes, business accounts only return the
+// QuickXorHash.
+func (hashes *FileHashes) Strongest() (HashAlgorithm, string) {
+	switch {
+	case hashes.SHA256 != "":
+		return HashAlgorithmSHA256, hashes.SHA256
+	case hashes.SHA1 != "":
+		return HashAlgorithmSHA1, hashes.SHA1
+	case hashes.QuickXOR != "":
+		return HashAlgorithmQuickXor, hashes.QuickXOR
+	default:
+		return "", ""
+	}
+}
+
+func newHash(algorithm HashAlgorithm) hash.Hash {
+	switch algorithm {
+	case HashAlgorithmSHA256:
+		return sha256.New()
+	case HashAlgorithmSHA1:
+		return sha1.New()
+	case HashAlgorithmQuickXor:
+		return NewQuickXorHash()
+	default:
+		return nil
+	}
+}
+
+// encodeHash encodes a digest the way the Graph API returns it.
+func encodeHash(algorithm HashAlgorithm, sum []byte) string {
+	if algorithm == HashAlgorithmQuickXor {
+		return base64.StdEncoding.EncodeToString(sum)
+	}
+	return strings.ToUpper(hex.EncodeToString(sum))
+}
+
+func hashesEqual(algorithm HashAlgorithm, expected, actual string) bool {
+	if algorithm == HashAlgorithmQuickXor {
+		return expected == actual
+	}
+	return strings.EqualFold(expected, actual)
+}
+
+// localFileMatchesHashes compares a local file with the strongest remote hash
+// available. The second result is false if no hash could be compared.
+func localFileMatchesHashes(localFilePath string, hashes *FileHashes) (bool, bool, error) {
+	algorithm, expected := hashes.Strongest()
+	if algorithm == "" {
+		return false, false, nil
+	}
+	actual, err := HashLocalFile(localFilePath, algorithm)
+	if err != nil {
+		return false, false, err
+	}
+	return hashesEqual(algorithm, expected, actual), true, nil
+}
+
+// HashLocalFile computes the hash of a local file, encoded the way the Graph
+// API returns it.
+func HashLocalFile(localFilePath string, algorithm HashAlgorithm) (string, error) {
+	h := newHash(algorithm)
+	if h == nil {
+		return "", ErrUnknownHashAlgorithm
+	}
+	f, err := os.Open(localFilePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return encodeHash(algorithm, h.Sum(nil)), nil
+}
+
+// IntegrityError is returned if the hash of a transferred file doesn't match
+// the hash reported by the remote side.
+type IntegrityError struct {
+	Path      string
+	Algorithm HashAlgorithm
+	Expected  string
+	Actual    string
+}
+
+func (e *IntegrityError) Error() string {
+	return "integrity check failed for " + e.Path + ": expected " + string(e.Algorithm) + " hash " + e.Expected + ", got " + e.Actual
+}
+
+var ErrNoHashAvailable = errors.New("remote item provides no hash for verification")
+
+// transferHasher computes all hashes supported by OneDrive while data is
+// written to it, so the result can be compared with whichever hash the
+// remote side provides.
+type transferHasher struct {
+	hashes map[HashAlgorithm]hash.Hash
+	writer io.Writer
+}
+
+func newTransferHasher() *transferHasher {
+	h := &transferHasher{
+		hashes: map[HashAlgorithm]hash.Hash{
+			HashAlgorithmSHA256:   newHash(HashAlgorithmSHA256),
+			HashAlgorithmSHA1:     newHash(HashAlgorithmSHA1),
+			HashAlgorithmQuickXor: newHash(HashAlgorithmQuickXor),
+		},
+	}
+	h.writer = io.MultiWriter(h.hashes[HashAlgorithmSHA256], h.hashes[HashAlgorithmSHA1], h.hashes[HashAlgorithmQuickXor])
+	return h
+}
+
+func (h *transferHasher) Write(p []byte) (int, error) {
+	return h.writer.Write(p)
+}
+
+func (h *transferHasher) Reset() {
+	for _, hash := range h.hashes {
+		hash.Reset()
+	}
+}
+
+// Verify compares the computed hash with the strongest remote hash.
+func (h *transferHasher) Verify(path string, hashes *FileHashes) error {
+	algorithm, expected := hashes.Strongest()
+	if algorithm == "" {
+		return ErrNoHashAvailable
+	}
+	actual := encodeHash(algorithm, h.hashes[algorithm].Sum(nil))
+	if !hashesEqual(algorithm, expected, actual) {
+		return &IntegrityError{
+			Path:      path,
+			Algorithm: algorithm,
+			Expected:  expected,
+			Actual:    actual,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileHashesStrongest(t *testing.T) {
+	hashes := &FileHashes{QuickXOR: "q", SHA1: "s1", SHA256: "s256"}
+	algorithm, value := hashes.Strongest()
+	checkTestBool(t, true, algorithm == HashAlgorithmSHA256)
+	checkTestString(t, "s256", value)
+	hashes = &FileHashes{QuickXOR: "q"}
+	algorithm, value = hashes.Strongest()
+	checkTestBool(t, true, algorithm == HashAlgorithmQuickXor)
+	checkTestString(t, "q", value)
+	hashes = &FileHashes{}
+	algorithm, _ = hashes.Strongest()
+	checkTestBool(t, true, algorithm == "")
+}
+
+func TestLocalFileMatchesHashes(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "j.txt")
+	os.WriteFile(fileName, []byte("J"), 0600)
+	equal, compared, err := localFileMatchesHashes(fileName, &FileHashes{QuickXOR: "SgAAAAAAAAAAAAAAAQAAAAAAAAA="})
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, compared)
+	checkTestBool(t, true, equal)
+	equal, compared, err = localFileMatchesHashes(fileName, &FileHashes{SHA1: "58668e7669fd564d99db5d581fcdb6a5618440b5"})
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, compared)
+	checkTestBool(t, true, equal)
+	_, compared, _ = localFileMatchesHashes(fileName, &FileHashes{})
+	checkTestBool(t, false, compared)
+}
+
+func TestTransferHasherVerify(t *testing.T) {
+	h := newTransferHasher()
+	h.Write([]byte("J"))
+	err := h.Verify("/j.txt", &FileHashes{QuickXOR: "SgAAAAAAAAAAAAAAAQAAAAAAAAA="})
+	checkTestBool(t, true, err == nil)
+	err = h.Verify("/j.txt", &FileHashes{SHA1: "58668E7669FD564D99DB5D581FCDB6A5618440B5"})
+	checkTestBool(t, true, err == nil)
+	err = h.Verify("/j.txt", &FileHashes{SHA1: "0000000000000000000000000000000000000000"})
+	integrityErr, ok := err.(*IntegrityError)
+	checkTestBool(t, true, ok)
+	checkTestBool(t, true, integrityErr.Algorithm == HashAlgorithmSHA1)
+	checkTestString(t, "58668E7669FD564D99DB5D581FCDB6A5618440B5", integrityErr.Actual)
+	err = h.Verify("/j.txt", &FileHashes{})
+	checkTestBool(t, true, err == ErrNoHashAvailable)
+}
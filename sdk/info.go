@@ -23,7 +23,7 @@ func (client *Client) Info(path string) (*DriveItem, error) {
 		return nil, err
 	}
 	if status == http.StatusNotFound {
-		return nil, errors.New("path not found")
+		return nil, ErrNotFound
 	}
 	if status != http.StatusOK {
 		return nil, client.handleResponseError(status, data)
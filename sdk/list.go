@@ -11,6 +11,8 @@ type ListResponse struct {
 	NextLink string      `json:"@odata.nextLink"`
 }
 
+// List returns the items in the remote folder, following the next links if
+// the listing is split into several pages.
 func (client *Client) List(path string) ([]*DriveItem, error) {
 	if len(path) > 0 && path[0] == '.' {
 		return nil, errors.New("invalid path (should start with /)")
@@ -27,29 +29,52 @@ func (client *Client) List(path string) ([]*DriveItem, error) {
 		"top":     "100000",
 		"orderby": "name",
 	}
-	status, data, err := client.httpGet(url, params)
+	return client.listPaged(url, params)
+}
+
+// listPaged fetches all items of a collection, following the next links of
+// paged responses.
+func (client *Client) listPaged(url string, params HTTPRequestParams) ([]*DriveItem, error) {
+	items, err := getPaged[*DriveItem](client, url, params)
 	if err != nil {
 		return nil, err
 	}
-	if status == http.StatusNotFound {
-		return nil, errors.New("path not found")
-	}
-	if status != http.StatusOK {
-		return nil, client.handleResponseError(status, data)
-	}
-	var resp ListResponse
-	if err := UnmarshalJSON(&resp, data); err != nil {
-		return nil, err
-	}
-	var result []*DriveItem
-	for i := range resp.Items {
-		driveItem := &resp.Items[i]
+	for _, driveItem := range items {
 		if driveItem.File.MimeType != "" {
 			driveItem.Type = DriveItemTypeFile
 		} else {
 			driveItem.Type = DriveItemTypeFolder
 		}
-		result = append(result, driveItem)
+	}
+	return items, nil
+}
+
+// getPaged fetches all values of a collection of any type, following the next
+// links of paged responses.
+func getPaged[T any](client *Client, url string, params HTTPRequestParams) ([]T, error) {
+	var result []T
+	for url != "" {
+		status, data, err := client.httpGet(url, params)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		if status != http.StatusOK {
+			return nil, client.handleResponseError(status, data)
+		}
+		var resp struct {
+			Values   []T    `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := UnmarshalJSON(&resp, data); err != nil {
+			return nil, err
+		}
+		result = append(result, resp.Values...)
+		// The next link already contains all parameters
+		url = resp.NextLink
+		params = nil
 	}
 	return result, nil
 }
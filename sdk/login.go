@@ -2,9 +2,15 @@ package sdk
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"html"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -28,10 +34,51 @@ const (
 	loginHTMLResponseOK     = "<p>Received authorization code from Microsoft Graph API.</p>" +
 		"<p>Please return to your terminal now.</p>"
 	loginHTMLResponseNotFound = "<p>Error: Page not found.</p>"
+	loginHTMLResponseError    = "<p>Login failed: %s</p>" +
+		"<p>Please return to your terminal and try again.</p>"
 )
 
+// LoginError is returned if the authorization server redirects back with an
+// error instead of an authorization code.
+type LoginError struct {
+	Code        string
+	Description string
+}
+
+func (e *LoginError) Error() string {
+	if e.Description == "" {
+		return "login failed: " + e.Code
+	}
+	return "login failed: " + e.Description + " (" + e.Code + ")"
+}
+
+var ErrLoginStateMismatch = errors.New("login failed: state parameter does not match (possible CSRF attempt)")
+
 func (client *Client) Login() error {
-	code := client.expectCode()
+	code, err := client.expectCode()
+	if err != nil {
+		return err
+	}
+	return client.finishLogin(code)
+}
+
+// LoginWithCode completes the login without the local callback server.
+// The input may either be the full URL the browser was redirected to or the
+// bare value of its code parameter. A URL must contain the state sent by
+// GetLoginURL.
+func (client *Client) LoginWithCode(input string) error {
+	code, state, err := parseAuthorizationCode(input)
+	if err != nil {
+		return err
+	}
+	isBareCode := code == strings.TrimSpace(input)
+	if !isBareCode && state != client.loginState {
+		return ErrLoginStateMismatch
+	}
+	return client.finishLogin(code)
+}
+
+func (client *Client) finishLogin(code string) error {
 	grant, err := client.redeemCodeForAccessToken(code)
 	if err != nil {
 		return err
@@ -42,6 +89,49 @@ func (client *Client) Login() error {
 	return nil
 }
 
+func parseAuthorizationCode(input string) (string, string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", "", errors.New("no code specified")
+	}
+	query := ""
+	if i := strings.Index(input, "?"); i > -1 {
+		query = input[i+1:]
+	} else if strings.Contains(input, "=") {
+		query = input
+	} else {
+		return input, "", nil
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", err
+	}
+	if err := loginErrorFromQuery(values); err != nil {
+		return "", "", err
+	}
+	code := values.Get("code")
+	if code == "" {
+		return "", "", errors.New("no code parameter found in input")
+	}
+	return code, values.Get("state"), nil
+}
+
+func loginErrorFromQuery(values url.Values) error {
+	if values.Get("error") == "" {
+		return nil
+	}
+	return &LoginError{
+		Code:        values.Get("error"),
+		Description: values.Get("error_description"),
+	}
+}
+
+func generateLoginState() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
 func (client *Client) UpdateSecretStore(grant *LoginRedeemCodeResponse) error {
 	expiry := time.Now().Add(time.Second * time.Duration(grant.ExpiresIn))
 	client.Config.AccessToken = grant.AccessToken
@@ -50,12 +140,16 @@ func (client *Client) UpdateSecretStore(grant *LoginRedeemCodeResponse) error {
 	return client.Config.Write()
 }
 
+// GetLoginURL returns the URL to start the login with. Each call generates a
+// new random state which the redirect is validated against.
 func (client *Client) GetLoginURL() string {
+	client.loginState = generateLoginState()
 	params := make(HTTPRequestParams)
 	params["client_id"] = client.Config.ClientID
 	params["scope"] = strings.Join(client.Config.Scopes, " ")
 	params["response_type"] = "code"
 	params["redirect_uri"] = client.Config.RedirectURL
+	params["state"] = client.loginState
 	uri := client.buildURI("https://login.microsoftonline.com/common/oauth2/v2.0/authorize", params)
 	return uri
 }
@@ -91,6 +185,16 @@ func (client *Client) ShouldRenewAccessToken() bool {
 	return diff.Minutes() > -30
 }
 
+// EnsureAccessToken renews the access token if it's about to expire. Long
+// running operations should call it before each request.
+func (client *Client) EnsureAccessToken() error {
+	if !client.ShouldRenewAccessToken() {
+		return nil
+	}
+	_, err := client.RenewAccessToken()
+	return err
+}
+
 func (client *Client) RenewAccessToken() (*LoginRedeemCodeResponse, error) {
 	params := make(HTTPRequestParams)
 	params["client_id"] = client.Config.ClientID
@@ -113,9 +217,11 @@ func (client *Client) RenewAccessToken() (*LoginRedeemCodeResponse, error) {
 	return &json, nil
 }
 
-func (client *Client) expectCode() string {
+func (client *Client) expectCode() (string, error) {
+	mux := http.NewServeMux()
 	httpServer := &http.Server{
 		Addr:         "0.0.0.0:53682",
+		Handler:      mux,
 		WriteTimeout: time.Second * 15,
 		ReadTimeout:  time.Second * 15,
 		IdleTimeout:  time.Second * 60,
@@ -123,32 +229,55 @@ func (client *Client) expectCode() string {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	code := ""
-	var handleCall = func(w http.ResponseWriter, r *http.Request) {
-		s := r.URL.Query().Get("code")
-		if s != "" {
-			html := loginHTMLResponseHeader + loginHTMLResponseOK + loginHTMLResponseFooter
-			w.Header().Set("Content-Type", "text/html")
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte(html))
-			if f, ok := w.(http.Flusher); ok {
-				f.Flush()
-			}
-			code = s
+	var resErr error
+	mux.HandleFunc("/", client.loginCallbackHandler(func(c string, err error) {
+		code, resErr = c, err
+		cancel()
+	}))
+
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			resErr = err
 			cancel()
-		} else {
-			html := loginHTMLResponseHeader + loginHTMLResponseNotFound + loginHTMLResponseFooter
-			w.Header().Set("Content-Type", "text/html")
-			w.WriteHeader(http.StatusNotFound)
-			w.Write([]byte(html))
 		}
-	}
-	http.HandleFunc("/", handleCall)
-
-	go func() error {
-		return httpServer.ListenAndServe()
 	}()
 	<-ctx.Done()
 	httpServer.Shutdown(context.Background())
 
-	return code
+	return code, resErr
+}
+
+// loginCallbackHandler handles the redirect after login. done is called once
+// a code or an error with matching state has been received.
+func (client *Client) loginCallbackHandler(done func(code string, err error)) http.HandlerFunc {
+	var once sync.Once
+	var writeHTML = func(w http.ResponseWriter, status int, content string) {
+		html := loginHTMLResponseHeader + content + loginHTMLResponseFooter
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(status)
+		w.Write([]byte(html))
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("code") == "" && query.Get("error") == "" {
+			writeHTML(w, http.StatusNotFound, loginHTMLResponseNotFound)
+			return
+		}
+		// Requests not carrying our state are answered, but don't end the
+		// login, so a stray or forged request can't abort it.
+		if query.Get("state") != client.loginState {
+			writeHTML(w, http.StatusBadRequest, fmt.Sprintf(loginHTMLResponseError, html.EscapeString(ErrLoginStateMismatch.Error())))
+			return
+		}
+		if err := loginErrorFromQuery(query); err != nil {
+			writeHTML(w, http.StatusBadRequest, fmt.Sprintf(loginHTMLResponseError, html.EscapeString(err.Error())))
+			once.Do(func() { done("", err) })
+			return
+		}
+		writeHTML(w, http.StatusOK, loginHTMLResponseOK)
+		once.Do(func() { done(query.Get("code"), nil) })
+	}
 }
@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseAuthorizationCodeBare(t *testing.T) {
+	code, state, err := parseAuthorizationCode(" M.C507_BAY.2.U.1234-abcd \n")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "M.C507_BAY.2.U.1234-abcd", code)
+	checkTestString(t, "", state)
+}
+
+func TestParseAuthorizationCodeURL(t *testing.T) {
+	code, state, err := parseAuthorizationCode("http://localhost:53682/?code=M.C507_BAY.2.U.1234-abcd&state=abc123")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "M.C507_BAY.2.U.1234-abcd", code)
+	checkTestString(t, "abc123", state)
+}
+
+func TestParseAuthorizationCodeQuery(t *testing.T) {
+	code, _, err := parseAuthorizationCode("code=M.C507_BAY.2.U.1234-abcd")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "M.C507_BAY.2.U.1234-abcd", code)
+}
+
+func TestParseAuthorizationCodeMissing(t *testing.T) {
+	_, _, err := parseAuthorizationCode("http://localhost:53682/?foo=bar")
+	checkTestBool(t, false, err == nil)
+	_, _, err = parseAuthorizationCode("   ")
+	checkTestBool(t, false, err == nil)
+}
+
+func TestParseAuthorizationCodeError(t *testing.T) {
+	_, _, err := parseAuthorizationCode("http://localhost:53682/?error=access_denied&error_description=The+user+has+denied+access")
+	loginErr, ok := err.(*LoginError)
+	checkTestBool(t, true, ok)
+	checkTestString(t, "access_denied", loginErr.Code)
+	checkTestString(t, "The user has denied access", loginErr.Description)
+}
+
+func TestLoginWithCodeStateMismatch(t *testing.T) {
+	c := &Client{loginState: "expected"}
+	err := c.LoginWithCode("http://localhost:53682/?code=abc&state=other")
+	checkTestBool(t, true, err == ErrLoginStateMismatch)
+}
+
+func TestLoginWithCodeStateMissing(t *testing.T) {
+	c := &Client{loginState: "expected"}
+	err := c.LoginWithCode("http://localhost:53682/?code=abc")
+	checkTestBool(t, true, err == ErrLoginStateMismatch)
+	err = c.LoginWithCode("code=abc")
+	checkTestBool(t, true, err == ErrLoginStateMismatch)
+}
+
+func TestLoginCallbackIgnoresForeignState(t *testing.T) {
+	c := &Client{loginState: "expected"}
+	calls := 0
+	code := ""
+	handler := c.loginCallbackHandler(func(s string, err error) {
+		calls++
+		code = s
+		checkTestBool(t, true, err == nil)
+	})
+	request := func(query string) int {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+		return rec.Code
+	}
+
+	checkTestInt(t, http.StatusNotFound, request("foo=bar"))
+	checkTestInt(t, http.StatusBadRequest, request("code=forged&state=other"))
+	checkTestInt(t, http.StatusBadRequest, request("code=forged"))
+	checkTestInt(t, http.StatusBadRequest, request("error=access_denied&state=other"))
+	checkTestInt(t, 0, calls)
+	checkTestInt(t, http.StatusOK, request("code=abc&state=expected"))
+	checkTestInt(t, 1, calls)
+	checkTestString(t, "abc", code)
+}
+
+func TestGetLoginURLState(t *testing.T) {
+	c := CreateClient(&Config{ClientID: "id", RedirectURL: "http://localhost:53682/"})
+	u1 := c.GetLoginURL()
+	state1 := c.loginState
+	u2 := c.GetLoginURL()
+	checkTestBool(t, true, state1 != "")
+	checkTestBool(t, true, state1 != c.loginState)
+	checkTestBool(t, true, strings.Contains(u1, "state="+state1))
+	checkTestBool(t, true, strings.Contains(u2, "state="+c.loginState))
+}
@@ -2,6 +2,8 @@ package sdk
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"runtime/debug"
 	"testing"
@@ -57,3 +59,16 @@ func checkTestInt(t *testing.T, expected, actual int) {
 		t.Fatalf("Expected '%d', but got '%d' at:\n%s", expected, actual, debug.Stack())
 	}
 }
+
+// newTestClient returns a client talking to a test server with the given
+// handler instead of the Graph API.
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	ts := httptest.NewServer(handler)
+	oldGraphURL := GraphURL
+	GraphURL = ts.URL + "/"
+	t.Cleanup(func() {
+		GraphURL = oldGraphURL
+		ts.Close()
+	})
+	return CreateClient(&Config{Root: "/drive/root", AccessToken: "token"})
+}
@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"path"
+)
+
+type MoveRequest struct {
+	ParentReference ItemReference `json:"parentReference"`
+	Name            string        `json:"name"`
+}
+
+// Move moves the remote item at src to the path dst, which may be in another
+// folder, have another name or both. Missing parent folders of dst are
+// created.
+func (client *Client) Move(src, dst string) error {
+	if (len(src) > 0 && src[0] == '.') || (len(dst) > 0 && dst[0] == '.') {
+		return errors.New("invalid path (should start with /)")
+	}
+	src = path.Clean("/" + src)
+	dst = path.Clean("/" + dst)
+	if src == "/" || dst == "/" {
+		return errors.New("cannot move the root folder")
+	}
+	parentPath := path.Dir(dst)
+	if err := client.CreateDirAll(parentPath); err != nil {
+		return err
+	}
+	parent, err := client.Info(parentPath)
+	if err != nil {
+		return err
+	}
+	req := &MoveRequest{
+		ParentReference: ItemReference{
+			ID: parent.ID,
+		},
+		Name: client.SanitizeFileName(path.Base(dst)),
+	}
+	url := GraphURL + "me" + client.Config.Root + ":" + src
+	if client.ConflictBehavior != "" {
+		params := make(HTTPRequestParams)
+		params["@microsoft.graph.conflictBehavior"] = string(client.ConflictBehavior)
+		url = client.buildURI(url, params)
+	}
+	status, data, err := client.httpSendJSON("PATCH", url, req)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if status == http.StatusConflict {
+		return &ConflictError{Path: dst}
+	}
+	if !IsHTTPStatusOK(status) {
+		return client.handleResponseError(status, data)
+	}
+	return nil
+}
+
+// CreateDirAll creates the remote folder at p along with any missing parent
+// folders. Existing folders are left untouched.
+func (client *Client) CreateDirAll(p string) error {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return nil
+	}
+	_, err := client.Info(p)
+	if err == nil {
+		return nil
+	}
+	if err != ErrNotFound {
+		return err
+	}
+	if err := client.CreateDirAll(path.Dir(p)); err != nil {
+		return err
+	}
+	return client.CreateDir(p)
+}
@@ -0,0 +1,42 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestMoveCreatesParent(t *testing.T) {
+	var created []string
+	var moved MoveRequest
+	var movedPath string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/me/drive/root:/archive" && len(created) == 0:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET":
+			w.Write([]byte(`{"id":"archive-id","name":"archive","folder":{"childCount":0}}`))
+		case r.Method == "POST":
+			var req CreateFolderRequest
+			json.Unmarshal(data, &req)
+			created = append(created, req.Name)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case r.Method == "PATCH":
+			movedPath = r.URL.Path
+			json.Unmarshal(data, &moved)
+			w.Write([]byte(`{}`))
+		}
+	}))
+
+	err := client.Move("/backup.tar.gz", "/archive/backup-1.tar.gz")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(created))
+	checkTestString(t, "archive", created[0])
+	checkTestString(t, "/me/drive/root:/backup.tar.gz", movedPath)
+	checkTestString(t, "archive-id", moved.ParentReference.ID)
+	checkTestString(t, "backup-1.tar.gz", moved.Name)
+}
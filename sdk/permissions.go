@@ -0,0 +1,224 @@
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type LinkType string
+
+const (
+	LinkTypeView  LinkType = "view"
+	LinkTypeEdit  LinkType = "edit"
+	LinkTypeEmbed LinkType = "embed"
+)
+
+type LinkScope string
+
+const (
+	// Anyone with the link has access
+	LinkScopeAnonymous LinkScope = "anonymous"
+	// Anyone signed in to the organization has access
+	LinkScopeOrganization LinkScope = "organization"
+)
+
+type PermissionRole string
+
+const (
+	PermissionRoleRead  PermissionRole = "read"
+	PermissionRoleWrite PermissionRole = "write"
+)
+
+type SharingInvitation struct {
+	Email          string `json:"email"`
+	SignInRequired bool   `json:"signInRequired"`
+}
+
+type SharingLink struct {
+	Type   LinkType  `json:"type"`
+	Scope  LinkScope `json:"scope"`
+	WebURL string    `json:"webUrl"`
+}
+
+type Permission struct {
+	ID            string             `json:"id"`
+	Roles         []string           `json:"roles"`
+	Link          *SharingLink       `json:"link,omitempty"`
+	GrantedTo     *IdentitySet       `json:"grantedToV2,omitempty"`
+	Invitation    *SharingInvitation `json:"invitation,omitempty"`
+	InheritedFrom *ItemReference     `json:"inheritedFrom,omitempty"`
+	Expiry        time.Time          `json:"expirationDateTime,omitzero"`
+	HasPassword   bool               `json:"hasPassword"`
+}
+
+// Grantee returns a description of who is granted the permission.
+func (p *Permission) Grantee() string {
+	if p.GrantedTo != nil {
+		if name := p.GrantedTo.Name(); name != "" {
+			return name
+		}
+	}
+	if p.Invitation != nil && p.Invitation.Email != "" {
+		return p.Invitation.Email
+	}
+	if p.Link != nil {
+		return "link (" + string(p.Link.Scope) + ")"
+	}
+	return ""
+}
+
+type PermissionsResponse struct {
+	Permissions []*Permission `json:"value"`
+}
+
+type DriveRecipient struct {
+	Email string `json:"email"`
+}
+
+type InviteRequest struct {
+	Recipients     []DriveRecipient `json:"recipients"`
+	Message        string           `json:"message,omitempty"`
+	RequireSignIn  bool             `json:"requireSignIn"`
+	SendInvitation bool             `json:"sendInvitation"`
+	Roles          []PermissionRole `json:"roles"`
+}
+
+type CreateLinkRequest struct {
+	Type     LinkType  `json:"type"`
+	Scope    LinkScope `json:"scope,omitempty"`
+	Expiry   string    `json:"expirationDateTime,omitempty"`
+	Password string    `json:"password,omitempty"`
+}
+
+// CreateLink creates a sharing link for the remote item. If no scope is
+// given, the default scope of the account is used. A zero expiry creates a
+// link which doesn't expire. Expiry and password are not supported by all
+// account types.
+func (client *Client) CreateLink(path string, linkType LinkType, scope LinkScope, expiry time.Time, password string) (*Permission, error) {
+	url, err := client.itemURL(path)
+	if err != nil {
+		return nil, err
+	}
+	req := &CreateLinkRequest{
+		Type:     linkType,
+		Scope:    scope,
+		Password: password,
+	}
+	if !expiry.IsZero() {
+		req.Expiry = expiry.UTC().Format(time.RFC3339)
+	}
+	status, data, err := client.httpPostJSON(url+"createLink", req)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if !IsHTTPStatusOK(status) {
+		return nil, client.handleResponseError(status, data)
+	}
+	var permission Permission
+	if err := UnmarshalJSON(&permission, data); err != nil {
+		return nil, err
+	}
+	return &permission, nil
+}
+
+// Permissions returns the permissions of the remote item, including sharing
+// links.
+func (client *Client) Permissions(path string) ([]*Permission, error) {
+	url, err := client.itemURL(path)
+	if err != nil {
+		return nil, err
+	}
+	status, data, err := client.httpGet(url+"permissions", nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		return nil, client.handleResponseError(status, data)
+	}
+	var resp PermissionsResponse
+	if err := UnmarshalJSON(&resp, data); err != nil {
+		return nil, err
+	}
+	return resp.Permissions, nil
+}
+
+// Invite grants the recipients access to the remote item with the given
+// role. Recipients have to sign in to access the item. If sendInvitation is
+// set, an email including the optional message is sent to the recipients.
+func (client *Client) Invite(path string, emails []string, role PermissionRole, message string, sendInvitation bool) ([]*Permission, error) {
+	if len(emails) == 0 {
+		return nil, errors.New("please specify at least one recipient")
+	}
+	url, err := client.itemURL(path)
+	if err != nil {
+		return nil, err
+	}
+	req := &InviteRequest{
+		Message:        message,
+		RequireSignIn:  true,
+		SendInvitation: sendInvitation,
+		Roles:          []PermissionRole{role},
+	}
+	for _, email := range emails {
+		req.Recipients = append(req.Recipients, DriveRecipient{Email: email})
+	}
+	status, data, err := client.httpPostJSON(url+"invite", req)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if !IsHTTPStatusOK(status) {
+		return nil, client.handleResponseError(status, data)
+	}
+	var resp PermissionsResponse
+	if err := UnmarshalJSON(&resp, data); err != nil {
+		return nil, err
+	}
+	return resp.Permissions, nil
+}
+
+// DeletePermission removes a permission from the remote item. Deleting the
+// permission of a sharing link revokes the link.
+func (client *Client) DeletePermission(path, permissionID string) error {
+	url, err := client.itemURL(path)
+	if err != nil {
+		return err
+	}
+	status, data, err := client.httpDelete(url + "permissions/" + permissionID)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if !IsHTTPStatusOK(status) {
+		return client.handleResponseError(status, data)
+	}
+	return nil
+}
+
+// itemURL returns the URL of the remote item, ending with a colon or slash
+// so that actions can be appended.
+func (client *Client) itemURL(path string) (string, error) {
+	if len(path) > 0 && path[0] == '.' {
+		return "", errors.New("invalid path (should start with /)")
+	}
+	path = strings.TrimSuffix(path, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if path == "/" {
+		return GraphURL + "me" + client.Config.Root + "/", nil
+	}
+	return GraphURL + "me" + client.Config.Root + ":" + path + ":/", nil
+}
@@ -0,0 +1,75 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSharingLinks(t *testing.T) {
+	var created CreateLinkRequest
+	var requests []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/me/drive/root:/builds/app.zip:/createLink":
+			json.Unmarshal(data, &created)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"p1","roles":["read"],"link":{"type":"view","scope":"anonymous","webUrl":"https://1drv.ms/u/abc"}}`))
+		case r.Method == "GET" && r.URL.Path == "/me/drive/root:/builds/app.zip:/permissions":
+			w.Write([]byte(`{"value":[{"id":"p1","roles":["read"],"link":{"type":"view","scope":"anonymous","webUrl":"https://1drv.ms/u/abc"},"expirationDateTime":"2026-12-31T00:00:00Z"}]}`))
+		case r.Method == "DELETE" && r.URL.Path == "/me/drive/root:/builds/app.zip:/permissions/p1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	expiry := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	permission, err := client.CreateLink("/builds/app.zip", LinkTypeView, LinkScopeAnonymous, expiry, "secret")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "https://1drv.ms/u/abc", permission.Link.WebURL)
+	checkTestString(t, "2026-12-31T00:00:00Z", created.Expiry)
+	checkTestString(t, "secret", created.Password)
+	checkTestString(t, "anonymous", string(created.Scope))
+
+	permissions, err := client.Permissions("/builds/app.zip")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(permissions))
+	checkTestBool(t, true, permissions[0].Expiry.Equal(expiry))
+
+	checkTestBool(t, true, client.DeletePermission("/builds/app.zip", "p1") == nil)
+	checkTestBool(t, true, client.DeletePermission("/builds/app.zip", "p2") == ErrNotFound)
+}
+
+func TestInvite(t *testing.T) {
+	var invited InviteRequest
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.Path != "/me/drive/root:/team:/invite" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.Unmarshal(data, &invited)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"value":[{"id":"p2","roles":["write"],"grantedToV2":{"user":{"displayName":"Bob"}}},{"id":"p3","roles":["write"],"invitation":{"email":"carol@example.com"}}]}`))
+	}))
+
+	permissions, err := client.Invite("/team", []string{"bob@example.com", "carol@example.com"}, PermissionRoleWrite, "Welcome", true)
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(invited.Recipients))
+	checkTestString(t, "carol@example.com", invited.Recipients[1].Email)
+	checkTestString(t, "write", string(invited.Roles[0]))
+	checkTestString(t, "Welcome", invited.Message)
+	checkTestBool(t, true, invited.RequireSignIn)
+	checkTestInt(t, 2, len(permissions))
+	checkTestString(t, "Bob", permissions[0].Grantee())
+	checkTestString(t, "carol@example.com", permissions[1].Grantee())
+
+	_, err = client.Invite("/team", nil, PermissionRoleRead, "", false)
+	checkTestBool(t, true, err != nil)
+}
@@ -0,0 +1,68 @@
+package sdk
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+// QuickXorHash is the hash algorithm used by OneDrive for all drive types.
+// See https://learn.microsoft.com/en-us/onedrive/developer/code-snippets/quickxorhash
+// It produces a 160 bit digest which the Graph API returns base64 encoded.
+
+const (
+	QuickXorHashSize      = 20
+	QuickXorHashBlockSize = 64
+	quickXorWidthInBits   = QuickXorHashSize * 8
+	quickXorShift         = 11
+)
+
+type quickXorHash struct {
+	data        [QuickXorHashSize]byte
+	shiftSoFar  int
+	lengthSoFar uint64
+}
+
+// NewQuickXorHash returns a new hash.Hash computing the QuickXorHash.
+func NewQuickXorHash() hash.Hash {
+	return &quickXorHash{}
+}
+
+func (h *quickXorHash) Write(p []byte) (int, error) {
+	// Byte i of the input is XORed in at bit position (shift * i) modulo 160
+	vectorOffset := h.shiftSoFar
+	for _, b := range p {
+		byteIndex := vectorOffset / 8
+		bitOffset := vectorOffset % 8
+		h.data[byteIndex] ^= b << bitOffset
+		if bitOffset > 0 {
+			h.data[(byteIndex+1)%QuickXorHashSize] ^= b >> (8 - bitOffset)
+		}
+		vectorOffset = (vectorOffset + quickXorShift) % quickXorWidthInBits
+	}
+	h.shiftSoFar = vectorOffset
+	h.lengthSoFar += uint64(len(p))
+	return len(p), nil
+}
+
+func (h *quickXorHash) Sum(b []byte) []byte {
+	res := h.data
+	// XOR the length into the last 8 bytes (little endian)
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], h.lengthSoFar)
+	for i := 0; i < 8; i++ {
+		res[QuickXorHashSize-8+i] ^= length[i]
+	}
+	return append(b, res[:]...)
+}
+
+func (h *quickXorHash) Reset() {
+	*h = quickXorHash{}
+}
+
+func (h *quickXorHash) Size() int {
+	return QuickXorHashSize
+}
+
+func (h *quickXorHash) BlockSize() int {
+	return QuickXorHashBlockSize
+}
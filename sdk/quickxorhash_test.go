@@ -0,0 +1,64 @@
+package sdk
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"testing"
+)
+
+func quickXorHashBase64(data []byte) string {
+	h := NewQuickXorHash()
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// quickXorHashReference computes the hash bit by bit
+func quickXorHashReference(data []byte) string {
+	var res [QuickXorHashSize]byte
+	for i, b := range data {
+		for k := 0; k < 8; k++ {
+			if b&(1<<k) == 0 {
+				continue
+			}
+			pos := (i*quickXorShift + k) % quickXorWidthInBits
+			res[pos/8] ^= 1 << (pos % 8)
+		}
+	}
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(data)))
+	for i := 0; i < 8; i++ {
+		res[12+i] ^= length[i]
+	}
+	return base64.StdEncoding.EncodeToString(res[:])
+}
+
+func TestQuickXorHashEmpty(t *testing.T) {
+	checkTestString(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAA=", quickXorHashBase64(nil))
+}
+
+func TestQuickXorHashSingleByte(t *testing.T) {
+	checkTestString(t, "SgAAAAAAAAAAAAAAAQAAAAAAAAA=", quickXorHashBase64([]byte{0x4a}))
+}
+
+func TestQuickXorHashRandom(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+	checkTestString(t, quickXorHashReference(data), quickXorHashBase64(data))
+}
+
+func TestQuickXorHashChunkedWrites(t *testing.T) {
+	data := make([]byte, 4099)
+	rand.Read(data)
+	h := NewQuickXorHash()
+	for i := 0; i < len(data); i += 7 {
+		end := i + 7
+		if end > len(data) {
+			end = len(data)
+		}
+		h.Write(data[i:end])
+	}
+	checkTestString(t, quickXorHashBase64(data), base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	h.Reset()
+	checkTestString(t, "AAAAAAAAAAAAAAAAAAAAAAAAAAA=", base64.StdEncoding.EncodeToString(h.Sum(nil)))
+}
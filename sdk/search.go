@@ -0,0 +1,123 @@
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Fields requested for search results
+const searchSelect = "id,name,size,file,folder,fileSystemInfo,parentReference"
+
+// Search returns the items matching query in folder and its sub folders. An
+// empty folder searches the whole drive. OneDrive matches the query against
+// file names, metadata and content.
+func (client *Client) Search(query, folder string) ([]*DriveItem, error) {
+	if len(folder) > 0 && folder[0] == '.' {
+		return nil, errors.New("invalid path (should start with /)")
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("please specify a search query")
+	}
+	folder = strings.TrimSuffix(folder, "/")
+	if folder != "" && !strings.HasPrefix(folder, "/") {
+		folder = "/" + folder
+	}
+	// Single quotes are escaped by doubling them
+	q := url.PathEscape(strings.ReplaceAll(query, "'", "''"))
+	uri := GraphURL + "me" + client.Config.Root + "/search(q='" + q + "')"
+	if folder != "" {
+		uri = GraphURL + "me" + client.Config.Root + ":" + folder + ":/search(q='" + q + "')"
+	}
+	params := HTTPRequestParams{
+		"$select": searchSelect,
+	}
+	return client.listPaged(uri, params)
+}
+
+// ErrPathUnknown is returned if the path of an item can't be determined,
+// e.g. because it is located outside of the configured root.
+var ErrPathUnknown = errors.New("path unknown")
+
+// ItemPath returns the path of the item relative to the configured root, so
+// it can be passed to the other methods. Search results often lack the path
+// of the parent, which is then looked up by the item's ID.
+func (client *Client) ItemPath(item *DriveItem) (string, error) {
+	parentPath := item.ParentReference.Path
+	if parentPath == "" {
+		if item.ID == "" {
+			return "", ErrPathUnknown
+		}
+		fullItem, err := client.infoByID(item.ID)
+		if err != nil {
+			return "", err
+		}
+		parentPath = fullItem.ParentReference.Path
+	}
+	if unescaped, err := url.PathUnescape(parentPath); err == nil {
+		parentPath = unescaped
+	}
+	prefixes, err := client.rootPathPrefixes()
+	if err != nil {
+		return "", err
+	}
+	for _, prefix := range prefixes {
+		if len(parentPath) < len(prefix) || !strings.EqualFold(parentPath[:len(prefix)], prefix) {
+			continue
+		}
+		rel := parentPath[len(prefix):]
+		if rel == "" || rel[0] == '/' {
+			return path.Join("/", rel, item.Name), nil
+		}
+	}
+	return "", ErrPathUnknown
+}
+
+// rootPathPrefixes returns the forms in which parent references may refer to
+// the configured root: the root as configured, e.g. "/drive/root:" or
+// "/drive/special/approot:", and its actual location in the drive, e.g.
+// "/drive/root:/Apps/Uploader".
+func (client *Client) rootPathPrefixes() ([]string, error) {
+	if client.rootPaths != nil {
+		return client.rootPaths, nil
+	}
+	res := []string{client.Config.Root + ":"}
+	if client.Config.Root != "/drive/root" {
+		root, err := client.Info("/")
+		if err != nil {
+			return nil, err
+		}
+		if root.ParentReference.Path != "" {
+			parentPath := root.ParentReference.Path
+			if unescaped, err := url.PathUnescape(parentPath); err == nil {
+				parentPath = unescaped
+			}
+			res = append(res, path.Join(parentPath, root.Name))
+		}
+	}
+	client.rootPaths = res
+	return res, nil
+}
+
+func (client *Client) infoByID(id string) (*DriveItem, error) {
+	params := HTTPRequestParams{
+		"$select": searchSelect,
+	}
+	status, data, err := client.httpGet(GraphURL+"me/drive/items/"+id, params)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status != http.StatusOK {
+		return nil, client.handleResponseError(status, data)
+	}
+	var item DriveItem
+	if err := UnmarshalJSON(&item, data); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchPaging(t *testing.T) {
+	var selects []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/me/drive/root:/docs:/search(q='bob''s report')":
+			selects = append(selects, r.URL.Query().Get("$select"))
+			w.Write([]byte(`{"value":[{"name":"report.pdf","size":10,"file":{"mimeType":"application/pdf"},"parentReference":{"path":"/drive/root:/docs/2026"}}],"@odata.nextLink":"` + GraphURL + `page2"}`))
+		case "/page2":
+			w.Write([]byte(`{"value":[{"name":"reports","folder":{"childCount":2},"parentReference":{"path":"/drive/root:/docs/My%20Files"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	items, err := client.Search("bob's report", "docs")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(items))
+	checkTestString(t, "/docs/2026/report.pdf", itemPath(t, client, items[0]))
+	checkTestBool(t, true, items[0].Type == DriveItemTypeFile)
+	checkTestString(t, "/docs/My Files/reports", itemPath(t, client, items[1]))
+	checkTestBool(t, true, items[1].Type == DriveItemTypeFolder)
+	checkTestInt(t, 1, len(selects))
+	checkTestString(t, searchSelect, selects[0])
+
+	checkTestString(t, "/a.txt", itemPath(t, client, &DriveItem{Name: "a.txt", ParentReference: ItemReference{Path: "/drive/root:"}}))
+}
+
+func itemPath(t *testing.T, client *Client, item *DriveItem) string {
+	p, err := client.ItemPath(item)
+	checkTestBool(t, true, err == nil)
+	return p
+}
+
+func TestItemPathAppRoot(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/me/drive/special/approot":
+			w.Write([]byte(`{"id":"root","name":"Uploader","folder":{"childCount":1},"parentReference":{"path":"/drive/root:/Apps"}}`))
+		case "/me/drive/items/item1":
+			w.Write([]byte(`{"id":"item1","name":"a.txt","parentReference":{"path":"/drive/root:/Apps/Uploader/My%20Docs"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	client.Config.Root = "/drive/special/approot"
+
+	checkTestString(t, "/docs/a.txt", itemPath(t, client, &DriveItem{Name: "a.txt", ParentReference: ItemReference{Path: "/drive/special/approot:/docs"}}))
+	checkTestString(t, "/b.txt", itemPath(t, client, &DriveItem{Name: "b.txt", ParentReference: ItemReference{Path: "/drive/root:/apps/uploader"}}))
+
+	// Search results without parent path are resolved by ID
+	checkTestString(t, "/My Docs/a.txt", itemPath(t, client, &DriveItem{ID: "item1", Name: "a.txt"}))
+
+	_, err := client.ItemPath(&DriveItem{Name: "c.txt", ParentReference: ItemReference{Path: "/drive/root:/Apps/UploaderOld"}})
+	checkTestBool(t, true, err == ErrPathUnknown)
+	_, err = client.ItemPath(&DriveItem{Name: "c.txt"})
+	checkTestBool(t, true, err == ErrPathUnknown)
+}
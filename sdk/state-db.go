@@ -0,0 +1,298 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StateItem is the locally indexed state of a remote drive item.
+type StateItem struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	ParentID     string     `json:"parent_id"`
+	ETag         string     `json:"etag"`
+	CTag         string     `json:"ctag"`
+	SizeBytes    int64      `json:"size"`
+	IsFolder     bool       `json:"folder"`
+	LastModified time.Time  `json:"last_modified"`
+	Hashes       FileHashes `json:"hashes"`
+}
+
+// DeletedStateItem is an indexed item which has been deleted from the drive.
+type DeletedStateItem struct {
+	StateItem
+	Path      string    `json:"path"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// StateDB is a local index of the remote drive, kept up to date using delta
+// queries. It is persisted as a JSON file.
+type StateDB struct {
+	FilePath  string                       `json:"-"`
+	DeltaLink string                       `json:"delta_link"`
+	RootID    string                       `json:"root_id"`
+	Items     map[string]*StateItem        `json:"items"`
+	Deleted   map[string]*DeletedStateItem `json:"deleted"`
+}
+
+type StateChangeType int
+
+const (
+	StateChangeCreated  StateChangeType = 1
+	StateChangeModified StateChangeType = 2
+	StateChangeDeleted  StateChangeType = 3
+)
+
+type StateChange struct {
+	Type StateChangeType
+	Path string
+	Item *StateItem
+}
+
+// OpenStateDB reads the index from filename. A missing file results in an
+// empty index which is written to filename on the first Write.
+func OpenStateDB(filename string) (*StateDB, error) {
+	db := &StateDB{
+		FilePath: filename,
+		Items:    make(map[string]*StateItem),
+		Deleted:  make(map[string]*DeletedStateItem),
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return db, nil
+		}
+		return nil, err
+	}
+	if err := UnmarshalJSON(db, data); err != nil {
+		return nil, err
+	}
+	if db.Items == nil {
+		db.Items = make(map[string]*StateItem)
+	}
+	if db.Deleted == nil {
+		db.Deleted = make(map[string]*DeletedStateItem)
+	}
+	return db, nil
+}
+
+// Write persists the index. The file is replaced atomically, so an
+// interrupted write never leaves a corrupt index behind.
+func (db *StateDB) Write() error {
+	data, err := json.Marshal(db)
+	if err != nil {
+		return err
+	}
+	tmpFile := db.FilePath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, db.FilePath)
+}
+
+// Reset clears the index, forcing a full enumeration on the next update.
+// Records of deleted items are kept.
+func (db *StateDB) Reset() {
+	db.DeltaLink = ""
+	db.RootID = ""
+	db.Items = make(map[string]*StateItem)
+}
+
+// Path returns the path of the item relative to the drive root, starting
+// with a slash. An empty string is returned for unknown items.
+func (db *StateDB) Path(id string) string {
+	parts := []string{}
+	for id != db.RootID {
+		item := db.Items[id]
+		if item == nil {
+			return ""
+		}
+		parts = append([]string{item.Name}, parts...)
+		id = item.ParentID
+	}
+	return "/" + strings.Join(parts, "/")
+}
+
+// Lookup returns the item at the given path or nil if it's not indexed.
+func (db *StateDB) Lookup(p string) *StateItem {
+	p = path.Clean("/" + p)
+	for id := range db.Items {
+		if db.Path(id) == p {
+			return db.Items[id]
+		}
+	}
+	return nil
+}
+
+// Tree returns all indexed items below dir, keyed by their relative path.
+func (db *StateDB) Tree(dir string) map[string]*DriveItem {
+	prefix := strings.TrimSuffix(path.Clean("/"+dir), "/") + "/"
+	res := make(map[string]*DriveItem)
+	for id, item := range db.Items {
+		p := db.Path(id)
+		if p == "" || !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		res[strings.TrimPrefix(p, prefix)] = item.DriveItem()
+	}
+	return res
+}
+
+// DriveItem converts the indexed state into a DriveItem.
+func (item *StateItem) DriveItem() *DriveItem {
+	driveItem := &DriveItem{
+		ID:        item.ID,
+		Name:      item.Name,
+		ETag:      item.ETag,
+		CTag:      item.CTag,
+		SizeBytes: item.SizeBytes,
+		FileSystemInfo: FileSystemInfo{
+			LastModified: item.LastModified,
+		},
+		ParentReference: ItemReference{
+			ID: item.ParentID,
+		},
+		Type: DriveItemTypeFile,
+	}
+	if item.IsFolder {
+		driveItem.Type = DriveItemTypeFolder
+	} else {
+		driveItem.File.Hashes = item.Hashes
+	}
+	return driveItem
+}
+
+// Apply merges delta items into the index and returns the resulting changes.
+func (db *StateDB) Apply(items []*DriveItem) []*StateChange {
+	var changes []*StateChange
+	if db.Deleted == nil {
+		db.Deleted = make(map[string]*DeletedStateItem)
+	}
+	for _, driveItem := range items {
+		if driveItem.ID == db.RootID {
+			continue
+		}
+		old := db.Items[driveItem.ID]
+		if driveItem.Deleted != nil {
+			if old == nil {
+				continue
+			}
+			p := db.Path(old.ID)
+			changes = append(changes, &StateChange{Type: StateChangeDeleted, Path: p, Item: old})
+			db.Deleted[old.ID] = &DeletedStateItem{StateItem: *old, Path: p, DeletedAt: time.Now()}
+			db.remove(old.ID)
+			continue
+		}
+		// The item might have been restored
+		delete(db.Deleted, driveItem.ID)
+		item := newStateItem(driveItem)
+		db.Items[item.ID] = item
+		if old == nil {
+			changes = append(changes, &StateChange{Type: StateChangeCreated, Item: item})
+		} else if old.Name != item.Name || old.ParentID != item.ParentID || (!item.IsFolder && old.CTag != item.CTag) {
+			changes = append(changes, &StateChange{Type: StateChangeModified, Item: item})
+		}
+	}
+	// Paths can only be resolved once all parents are known
+	for _, change := range changes {
+		if change.Type != StateChangeDeleted {
+			change.Path = db.Path(change.Item.ID)
+		}
+	}
+	return changes
+}
+
+func newStateItem(driveItem *DriveItem) *StateItem {
+	return &StateItem{
+		ID:           driveItem.ID,
+		Name:         driveItem.Name,
+		ParentID:     driveItem.ParentReference.ID,
+		ETag:         driveItem.ETag,
+		CTag:         driveItem.CTag,
+		SizeBytes:    driveItem.SizeBytes,
+		IsFolder:     driveItem.Type == DriveItemTypeFolder,
+		LastModified: driveItem.FileSystemInfo.LastModified,
+		Hashes:       driveItem.File.Hashes,
+	}
+}
+
+// RecordDeleted adds an item deleted by this client to the deleted items, so
+// it is known even if it has never been indexed. p is the item's path.
+func (db *StateDB) RecordDeleted(p string, driveItem *DriveItem) {
+	if db.Deleted == nil {
+		db.Deleted = make(map[string]*DeletedStateItem)
+	}
+	db.Deleted[driveItem.ID] = &DeletedStateItem{
+		StateItem: *newStateItem(driveItem),
+		Path:      path.Clean("/" + p),
+		DeletedAt: time.Now(),
+	}
+}
+
+// DeletedItems returns the recorded deleted items, most recently deleted
+// first.
+func (db *StateDB) DeletedItems() []*DeletedStateItem {
+	res := make([]*DeletedStateItem, 0, len(db.Deleted))
+	for _, item := range db.Deleted {
+		res = append(res, item)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].DeletedAt.Equal(res[j].DeletedAt) {
+			return res[i].DeletedAt.After(res[j].DeletedAt)
+		}
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+// LookupDeleted returns the most recently deleted item at the given path or
+// nil if there is none.
+func (db *StateDB) LookupDeleted(p string) *DeletedStateItem {
+	p = path.Clean("/" + p)
+	for _, item := range db.DeletedItems() {
+		if item.Path == p {
+			return item
+		}
+	}
+	return nil
+}
+
+func (db *StateDB) remove(id string) {
+	delete(db.Items, id)
+	for childID, item := range db.Items {
+		if item.ParentID == id {
+			db.remove(childID)
+		}
+	}
+}
+
+// UpdateStateDB fetches all changes since the last update and applies them
+// to the index. If the delta link has expired, the index is rebuilt.
+func (client *Client) UpdateStateDB(db *StateDB) ([]*StateChange, error) {
+	if db.RootID == "" {
+		root, err := client.Info("/")
+		if err != nil {
+			return nil, err
+		}
+		db.Reset()
+		db.RootID = root.ID
+	}
+	items, deltaLink, err := client.Delta(db.DeltaLink)
+	if errors.Is(err, ErrDeltaTokenExpired) {
+		rootID := db.RootID
+		db.Reset()
+		db.RootID = rootID
+		items, deltaLink, err = client.Delta("")
+	}
+	if err != nil {
+		return nil, err
+	}
+	changes := db.Apply(items)
+	db.DeltaLink = deltaLink
+	return changes, nil
+}
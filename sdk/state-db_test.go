@@ -0,0 +1,84 @@
+package sdk
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStateDBApply(t *testing.T) {
+	db := &StateDB{RootID: "root", Items: make(map[string]*StateItem)}
+	changes := db.Apply([]*DriveItem{
+		{ID: "root", Name: "root", Type: DriveItemTypeFolder},
+		{ID: "f1", Name: "docs", Type: DriveItemTypeFolder, ParentReference: ItemReference{ID: "root"}},
+		{ID: "i1", Name: "a.txt", CTag: "c1", SizeBytes: 3, Type: DriveItemTypeFile, ParentReference: ItemReference{ID: "f1"}},
+	})
+	checkTestInt(t, 2, len(changes))
+	checkTestString(t, "/docs", changes[0].Path)
+	checkTestString(t, "/docs/a.txt", changes[1].Path)
+	checkTestBool(t, true, changes[1].Type == StateChangeCreated)
+	checkTestString(t, "i1", db.Lookup("docs/a.txt").ID)
+
+	tree := db.Tree("/docs")
+	checkTestInt(t, 1, len(tree))
+	checkTestInt(t, 3, int(tree["a.txt"].SizeBytes))
+
+	// Unchanged content, new content and rename
+	changes = db.Apply([]*DriveItem{
+		{ID: "i1", Name: "a.txt", CTag: "c1", SizeBytes: 3, Type: DriveItemTypeFile, ParentReference: ItemReference{ID: "f1"}},
+	})
+	checkTestInt(t, 0, len(changes))
+	changes = db.Apply([]*DriveItem{
+		{ID: "i1", Name: "b.txt", CTag: "c2", SizeBytes: 4, Type: DriveItemTypeFile, ParentReference: ItemReference{ID: "f1"}},
+	})
+	checkTestInt(t, 1, len(changes))
+	checkTestBool(t, true, changes[0].Type == StateChangeModified)
+	checkTestString(t, "/docs/b.txt", changes[0].Path)
+
+	// Deleting the folder removes its children
+	changes = db.Apply([]*DriveItem{
+		{ID: "f1", Deleted: &DeletedItem{}},
+	})
+	checkTestInt(t, 1, len(changes))
+	checkTestBool(t, true, changes[0].Type == StateChangeDeleted)
+	checkTestString(t, "/docs", changes[0].Path)
+	checkTestInt(t, 0, len(db.Items))
+
+	// Deleted items are recorded until they are restored
+	checkTestInt(t, 1, len(db.DeletedItems()))
+	checkTestString(t, "f1", db.LookupDeleted("/docs").ID)
+	db.Apply([]*DriveItem{
+		{ID: "f1", Name: "docs", Type: DriveItemTypeFolder, ParentReference: ItemReference{ID: "root"}},
+	})
+	checkTestInt(t, 0, len(db.DeletedItems()))
+}
+
+func TestStateDBReadWrite(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "index.json")
+	db, err := OpenStateDB(fileName)
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 0, len(db.Items))
+	db.RootID = "root"
+	db.DeltaLink = "https://graph/delta?token=1"
+	db.Items["i1"] = &StateItem{ID: "i1", Name: "a.txt", ParentID: "root"}
+	checkTestBool(t, true, db.Write() == nil)
+
+	db, err = OpenStateDB(fileName)
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "https://graph/delta?token=1", db.DeltaLink)
+	checkTestString(t, "/a.txt", db.Path("i1"))
+}
+
+func TestStateDBRecordDeleted(t *testing.T) {
+	db, err := OpenStateDB(filepath.Join(t.TempDir(), "index.json"))
+	checkTestBool(t, true, err == nil)
+	db.RecordDeleted("old/report.pdf", &DriveItem{ID: "i1", Name: "report.pdf", SizeBytes: 3, Type: DriveItemTypeFile})
+	item := db.LookupDeleted("/old/report.pdf")
+	checkTestBool(t, true, item != nil)
+	checkTestString(t, "i1", item.ID)
+	checkTestString(t, "/old/report.pdf", item.Path)
+
+	// A deletion reported by a delta query for an item never indexed keeps
+	// the record
+	db.Apply([]*DriveItem{{ID: "i1", Deleted: &DeletedItem{}}})
+	checkTestInt(t, 1, len(db.DeletedItems()))
+}
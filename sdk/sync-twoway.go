@@ -0,0 +1,426 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type ConflictPolicy string
+
+const (
+	ConflictPolicyKeepBoth   ConflictPolicy = "keep-both"
+	ConflictPolicyNewestWins ConflictPolicy = "newest-wins"
+	ConflictPolicyLocalWins  ConflictPolicy = "local-wins"
+	ConflictPolicyRemoteWins ConflictPolicy = "remote-wins"
+)
+
+// ErrSyncSideEmpty is returned if one side of a two-way sync pair is empty
+// although the sync state contains items. Syncing would delete everything on
+// the other side, which is more likely caused by a wrong path than intended.
+// Set SyncOptions.AllowEmptySide to sync anyway.
+var ErrSyncSideEmpty = errors.New("local or remote folder is empty or missing, but was synced before")
+
+// SyncStateItem is the state of a file or folder as of the last successful
+// two-way sync.
+type SyncStateItem struct {
+	IsFolder     bool      `json:"folder"`
+	LocalSize    int64     `json:"local_size"`
+	LocalModTime time.Time `json:"local_mtime"`
+	RemoteCTag   string    `json:"remote_ctag"`
+}
+
+// SyncState holds the last synced state of every item of a two-way sync
+// pair, keyed by relative path. It is persisted as a JSON file.
+type SyncState struct {
+	FilePath string                    `json:"-"`
+	Items    map[string]*SyncStateItem `json:"items"`
+}
+
+// OpenSyncState reads the sync state from filename. A missing file results
+// in an empty state.
+func OpenSyncState(filename string) (*SyncState, error) {
+	state := &SyncState{
+		FilePath: filename,
+		Items:    make(map[string]*SyncStateItem),
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, err
+	}
+	if err := UnmarshalJSON(state, data); err != nil {
+		return nil, err
+	}
+	if state.Items == nil {
+		state.Items = make(map[string]*SyncStateItem)
+	}
+	return state, nil
+}
+
+// Write persists the sync state atomically.
+func (state *SyncState) Write() error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmpFile := state.FilePath + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, state.FilePath)
+}
+
+func (state *SyncState) remove(p string) {
+	p = strings.ToLower(p)
+	for itemPath := range state.Items {
+		key := strings.ToLower(itemPath)
+		if key == p || strings.HasPrefix(key, p+"/") {
+			delete(state.Items, itemPath)
+		}
+	}
+}
+
+func (state *SyncState) record(p string, l *SyncLocalItem, r *DriveItem) {
+	item := &SyncStateItem{
+		IsFolder: l.IsDir,
+	}
+	if !l.IsDir {
+		item.LocalSize = l.SizeBytes
+		item.LocalModTime = l.ModTime
+		item.RemoteCTag = r.CTag
+	}
+	// Drop entries of the same item recorded with a different case
+	for itemPath := range state.Items {
+		if strings.EqualFold(itemPath, p) {
+			delete(state.Items, itemPath)
+		}
+	}
+	state.Items[p] = item
+}
+
+// RecordUnchanged stores the state of all items existing on both sides which
+// no action was planned for. It should be called after all actions have been
+// executed successfully.
+func (state *SyncState) RecordUnchanged(local map[string]*SyncLocalItem, remote map[string]*DriveItem, actions []*SyncAction) {
+	planned := make(map[string]bool)
+	for _, action := range actions {
+		planned[strings.ToLower(action.Path)] = true
+	}
+	localByKey := foldKeys(local)
+	remoteByKey := foldKeys(remote)
+	for p, l := range local {
+		key := strings.ToLower(p)
+		r := remoteByKey[key]
+		if r == nil || planned[key] || l.IsDir != (r.Type == DriveItemTypeFolder) {
+			continue
+		}
+		state.record(p, l, r)
+	}
+	for p := range state.Items {
+		key := strings.ToLower(p)
+		if localByKey[key] == nil && remoteByKey[key] == nil && !planned[key] {
+			delete(state.Items, p)
+		}
+	}
+}
+
+// PlanTwoWaySync compares the local and the remote tree with the state of the
+// last sync and returns the actions needed to propagate changes in both
+// directions. Items changed on both sides are marked as conflicts and resolved
+// according to the conflict policy. Paths are compared case-insensitively, like
+// OneDrive does.
+func PlanTwoWaySync(localDir string, local map[string]*SyncLocalItem, remote map[string]*DriveItem, state *SyncState, opts SyncOptions) ([]*SyncAction, error) {
+	if !opts.AllowEmptySide && len(state.Items) > 0 && (len(local) == 0 || len(remote) == 0) {
+		return nil, ErrSyncSideEmpty
+	}
+	localByKey := foldKeys(local)
+	remoteByKey := foldKeys(remote)
+	stateByKey := foldKeys(state.Items)
+	// Each item is planned under its local path, if it exists locally
+	paths := make(map[string]string)
+	for p := range state.Items {
+		paths[strings.ToLower(p)] = p
+	}
+	for p := range remote {
+		paths[strings.ToLower(p)] = p
+	}
+	for p := range local {
+		paths[strings.ToLower(p)] = p
+	}
+	var mkdirs, transfers, deletes, folderDeletes []*SyncAction
+	for _, key := range sortedKeys(paths) {
+		p := paths[key]
+		l := localByKey[key]
+		r := remoteByKey[key]
+		st := stateByKey[key]
+		localPath := localPathFor(localDir, p, localByKey)
+		if l != nil {
+			localPath = l.LocalPath
+		}
+		if l != nil && r != nil && l.IsDir != (r.Type == DriveItemTypeFolder) {
+			return nil, errors.New("type mismatch between local and remote item: " + p)
+		}
+		if (l != nil && l.IsDir) || (r != nil && r.Type == DriveItemTypeFolder) || (l == nil && r == nil && st != nil && st.IsFolder) {
+			switch {
+			case l != nil && r == nil && st == nil:
+				mkdirs = append(mkdirs, &SyncAction{Type: SyncActionCreateDir, Path: p, LocalPath: localPath, Reason: "new local folder"})
+			case l == nil && r != nil && st == nil:
+				mkdirs = append(mkdirs, &SyncAction{Type: SyncActionCreateLocalDir, Path: p, LocalPath: localPath, Reason: "new remote folder"})
+			case l != nil && r == nil:
+				folderDeletes = append(folderDeletes, &SyncAction{Type: SyncActionDeleteLocal, Path: p, LocalPath: localPath, Reason: "deleted remotely"})
+			case l == nil && r != nil:
+				folderDeletes = append(folderDeletes, &SyncAction{Type: SyncActionDelete, Path: p, LocalPath: localPath, Reason: "deleted locally"})
+			}
+			continue
+		}
+		action, err := planTwoWayFile(p, localPath, l, r, st, opts)
+		if err != nil {
+			return nil, err
+		}
+		if action == nil {
+			continue
+		}
+		if action.Type == SyncActionDelete || action.Type == SyncActionDeleteLocal {
+			deletes = append(deletes, action)
+		} else {
+			transfers = append(transfers, action)
+		}
+	}
+	// A folder deleted on one side is only deleted on the other side if
+	// nothing below it has been added or changed there. Otherwise it is
+	// created again.
+	deletedFolders := make(map[string]bool)
+	for _, action := range folderDeletes {
+		if hasDeletedAncestor(strings.ToLower(action.Path), deletedFolders) {
+			continue
+		}
+		if containsSyncActionBelow(transfers, action.Path) || containsSyncActionBelow(mkdirs, action.Path) {
+			recreate := &SyncAction{Type: SyncActionCreateLocalDir, Path: action.Path, LocalPath: action.LocalPath, Reason: "recreated, contains changes"}
+			if action.Type == SyncActionDeleteLocal {
+				recreate.Type = SyncActionCreateDir
+			}
+			mkdirs = append(mkdirs, recreate)
+			continue
+		}
+		deletedFolders[strings.ToLower(action.Path)] = true
+	}
+	var res []*SyncAction
+	res = append(res, sortSyncActions(mkdirs)...)
+	res = append(res, transfers...)
+	for _, action := range deletes {
+		if !hasDeletedAncestor(strings.ToLower(action.Path), deletedFolders) {
+			res = append(res, action)
+		}
+	}
+	for _, action := range folderDeletes {
+		if deletedFolders[strings.ToLower(action.Path)] {
+			res = append(res, action)
+		}
+	}
+	return res, nil
+}
+
+// CountLocalDeletes returns the number of local items the actions delete,
+// including the items contained in deleted folders according to the state.
+func (state *SyncState) CountLocalDeletes(actions []*SyncAction) int {
+	res := 0
+	for _, action := range actions {
+		if action.Type != SyncActionDeleteLocal {
+			continue
+		}
+		dir := strings.ToLower(action.Path)
+		for p := range state.Items {
+			if key := strings.ToLower(p); key == dir || strings.HasPrefix(key, dir+"/") {
+				res++
+			}
+		}
+	}
+	return res
+}
+
+// localPathFor returns the local path of an item which doesn't exist locally,
+// based on the local path of its closest existing parent folder, which may
+// differ in case or have been sanitized.
+func localPathFor(localDir, p string, localByKey map[string]*SyncLocalItem) string {
+	for dir := path.Dir(p); dir != "."; dir = path.Dir(dir) {
+		if parent := localByKey[strings.ToLower(dir)]; parent != nil && parent.IsDir {
+			return filepath.Join(parent.LocalPath, filepath.FromSlash(p[len(dir)+1:]))
+		}
+	}
+	return filepath.Join(localDir, filepath.FromSlash(p))
+}
+
+func containsSyncActionBelow(actions []*SyncAction, dir string) bool {
+	dir = strings.ToLower(dir)
+	for _, action := range actions {
+		if strings.HasPrefix(strings.ToLower(action.Path), dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func planTwoWayFile(p, localPath string, l *SyncLocalItem, r *DriveItem, st *SyncStateItem, opts SyncOptions) (*SyncAction, error) {
+	localChanged := l != nil && (st == nil || l.SizeBytes != st.LocalSize || !l.ModTime.Equal(st.LocalModTime))
+	remoteChanged := r != nil && (st == nil || r.CTag != st.RemoteCTag)
+	upload := &SyncAction{Type: SyncActionUpload, Path: p, LocalPath: localPath}
+	download := &SyncAction{Type: SyncActionDownload, Path: p, LocalPath: localPath}
+	switch {
+	case l != nil && r != nil:
+		if !localChanged && !remoteChanged {
+			return nil, nil
+		}
+		if localChanged && !remoteChanged {
+			upload.Reason = "changed locally"
+			return upload, nil
+		}
+		if !localChanged && remoteChanged {
+			download.Reason = "changed remotely"
+			return download, nil
+		}
+		if l.SizeBytes == r.SizeBytes {
+			// Without state (e.g. on the first run) identical files would
+			// otherwise be conflicts.
+			if l.ModTime.Truncate(time.Second).Equal(r.FileSystemInfo.LastModified.Truncate(time.Second)) {
+				return nil, nil
+			}
+			equal, compared, err := localFileMatchesHashes(l.LocalPath, &r.File.Hashes)
+			if err != nil {
+				return nil, err
+			}
+			if compared && equal {
+				return nil, nil
+			}
+		}
+		return resolveSyncConflict(l, r, upload, download, opts.ConflictPolicy), nil
+	case l != nil && r == nil:
+		if st == nil || localChanged {
+			upload.Reason = "new locally"
+			if st != nil {
+				upload.Reason = "changed locally, deleted remotely"
+			}
+			return upload, nil
+		}
+		return &SyncAction{Type: SyncActionDeleteLocal, Path: p, LocalPath: localPath, Reason: "deleted remotely"}, nil
+	case l == nil && r != nil:
+		if st == nil || remoteChanged {
+			download.Reason = "new remotely"
+			if st != nil {
+				download.Reason = "changed remotely, deleted locally"
+			}
+			return download, nil
+		}
+		return &SyncAction{Type: SyncActionDelete, Path: p, LocalPath: localPath, Reason: "deleted locally"}, nil
+	}
+	return nil, nil
+}
+
+func resolveSyncConflict(l *SyncLocalItem, r *DriveItem, upload, download *SyncAction, policy ConflictPolicy) *SyncAction {
+	switch policy {
+	case ConflictPolicyLocalWins:
+		upload.Conflict = true
+		upload.Reason = "conflict, local wins"
+		return upload
+	case ConflictPolicyRemoteWins:
+		download.Conflict = true
+		download.Reason = "conflict, remote wins"
+		return download
+	case ConflictPolicyNewestWins:
+		if l.ModTime.After(r.FileSystemInfo.LastModified) {
+			upload.Conflict = true
+			upload.Reason = "conflict, local is newer"
+			return upload
+		}
+		download.Conflict = true
+		download.Reason = "conflict, remote is newer"
+		return download
+	default:
+		return &SyncAction{Type: SyncActionKeepBoth, Path: upload.Path, LocalPath: upload.LocalPath, Conflict: true, Reason: "conflict, keeping both"}
+	}
+}
+
+func sortSyncActions(actions []*SyncAction) []*SyncAction {
+	m := make(map[string]*SyncAction)
+	for _, action := range actions {
+		m[action.Path] = action
+	}
+	var res []*SyncAction
+	for _, p := range sortedKeys(m) {
+		res = append(res, m[p])
+	}
+	return res
+}
+
+// ConflictFileName returns the name a conflicting local copy is renamed to
+// when keeping both versions.
+func ConflictFileName(fileName string, t time.Time) string {
+	ext := path.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + ".conflict-" + t.Format("20060102-150405") + ext
+}
+
+// ExecuteTwoWaySyncAction performs a single action of a two-way sync plan and
+// records the resulting state, so an interrupted sync can safely be re-run.
+func (client *Client) ExecuteTwoWaySyncAction(remoteDir string, action *SyncAction, state *SyncState) error {
+	remotePath := path.Join("/", remoteDir, action.Path)
+	switch action.Type {
+	case SyncActionKeepBoth:
+		conflictPath := path.Join(path.Dir(action.Path), ConflictFileName(path.Base(action.Path), time.Now()))
+		conflictLocalPath := filepath.Join(filepath.Dir(action.LocalPath), path.Base(conflictPath))
+		if err := os.Rename(action.LocalPath, conflictLocalPath); err != nil {
+			return err
+		}
+		if err := client.Upload(conflictLocalPath, path.Dir(remotePath)); err != nil {
+			return err
+		}
+		if err := client.Download(remotePath, action.LocalPath); err != nil {
+			return err
+		}
+		action = &SyncAction{Type: SyncActionDownload, Path: action.Path, LocalPath: action.LocalPath}
+		conflictAction := &SyncAction{Type: SyncActionUpload, Path: conflictPath, LocalPath: conflictLocalPath}
+		if err := client.recordTwoWaySyncAction(remoteDir, conflictAction, state); err != nil {
+			return err
+		}
+	default:
+		if err := client.ExecuteSyncAction(remoteDir, action); err != nil {
+			return err
+		}
+	}
+	if err := client.recordTwoWaySyncAction(remoteDir, action, state); err != nil {
+		return err
+	}
+	return state.Write()
+}
+
+func (client *Client) recordTwoWaySyncAction(remoteDir string, action *SyncAction, state *SyncState) error {
+	if action.Type == SyncActionDelete || action.Type == SyncActionDeleteLocal {
+		state.remove(action.Path)
+		return nil
+	}
+	info, err := os.Stat(action.LocalPath)
+	if err != nil {
+		return err
+	}
+	l := &SyncLocalItem{
+		Path:      action.Path,
+		LocalPath: action.LocalPath,
+		SizeBytes: info.Size(),
+		ModTime:   info.ModTime(),
+		IsDir:     info.IsDir(),
+	}
+	r := &DriveItem{}
+	if !l.IsDir {
+		r, err = client.Info(path.Join("/", remoteDir, action.Path))
+		if err != nil {
+			return err
+		}
+	}
+	state.record(action.Path, l, r)
+	return nil
+}
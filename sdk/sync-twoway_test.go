@@ -0,0 +1,205 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testTwoWayState() (map[string]*SyncLocalItem, map[string]*DriveItem, *SyncState) {
+	t1 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	local := map[string]*SyncLocalItem{
+		"docs":         {Path: "docs", LocalPath: "/l/docs", IsDir: true},
+		"docs/a.txt":   {Path: "docs/a.txt", LocalPath: "/l/docs/a.txt", SizeBytes: 1, ModTime: t1},
+		"docs/b.txt":   {Path: "docs/b.txt", LocalPath: "/l/docs/b.txt", SizeBytes: 1, ModTime: t1},
+		"unchanged.md": {Path: "unchanged.md", LocalPath: "/l/unchanged.md", SizeBytes: 1, ModTime: t1},
+	}
+	remote := map[string]*DriveItem{
+		"docs":         {Name: "docs", Type: DriveItemTypeFolder},
+		"docs/a.txt":   {Name: "a.txt", CTag: "a1", SizeBytes: 1, Type: DriveItemTypeFile},
+		"docs/b.txt":   {Name: "b.txt", CTag: "b1", SizeBytes: 1, Type: DriveItemTypeFile},
+		"unchanged.md": {Name: "unchanged.md", CTag: "u1", SizeBytes: 1, Type: DriveItemTypeFile},
+	}
+	state := &SyncState{Items: make(map[string]*SyncStateItem)}
+	state.RecordUnchanged(local, remote, nil)
+	return local, remote, state
+}
+
+func TestPlanTwoWaySyncNoChanges(t *testing.T) {
+	local, remote, state := testTwoWayState()
+	checkTestInt(t, 4, len(state.Items))
+	actions, err := PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 0, len(actions))
+}
+
+func TestPlanTwoWaySyncChanges(t *testing.T) {
+	local, remote, state := testTwoWayState()
+	// Local change, remote change, new on both sides, deletions on both sides
+	local["docs/a.txt"].SizeBytes = 2
+	remote["docs/b.txt"].CTag = "b2"
+	local["new-local.txt"] = &SyncLocalItem{Path: "new-local.txt", LocalPath: "/l/new-local.txt", SizeBytes: 1}
+	remote["new-remote.txt"] = &DriveItem{Name: "new-remote.txt", CTag: "n1", Type: DriveItemTypeFile}
+	remote["new-folder"] = &DriveItem{Name: "new-folder", Type: DriveItemTypeFolder}
+	delete(remote, "unchanged.md")
+
+	actions, err := PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 6, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionCreateLocalDir)
+	checkTestString(t, filepath.FromSlash("/l/new-folder"), actions[0].LocalPath)
+	checkTestBool(t, true, actions[1].Type == SyncActionUpload)
+	checkTestString(t, "docs/a.txt", actions[1].Path)
+	checkTestBool(t, true, actions[2].Type == SyncActionDownload)
+	checkTestString(t, "docs/b.txt", actions[2].Path)
+	checkTestBool(t, true, actions[3].Type == SyncActionUpload)
+	checkTestString(t, "new-local.txt", actions[3].Path)
+	checkTestBool(t, true, actions[4].Type == SyncActionDownload)
+	checkTestString(t, "new-remote.txt", actions[4].Path)
+	checkTestBool(t, true, actions[5].Type == SyncActionDeleteLocal)
+	checkTestString(t, "unchanged.md", actions[5].Path)
+}
+
+func TestPlanTwoWaySyncConflict(t *testing.T) {
+	policies := map[ConflictPolicy]SyncActionType{
+		ConflictPolicyKeepBoth:   SyncActionKeepBoth,
+		ConflictPolicyLocalWins:  SyncActionUpload,
+		ConflictPolicyRemoteWins: SyncActionDownload,
+		ConflictPolicyNewestWins: SyncActionUpload,
+	}
+	for policy, expected := range policies {
+		local, remote, state := testTwoWayState()
+		local["docs/a.txt"].SizeBytes = 2
+		local["docs/a.txt"].ModTime = time.Now()
+		remote["docs/a.txt"].CTag = "a2"
+		actions, err := PlanTwoWaySync("/l", local, remote, state, SyncOptions{ConflictPolicy: policy})
+		checkTestBool(t, true, err == nil)
+		checkTestInt(t, 1, len(actions))
+		checkTestBool(t, true, actions[0].Conflict)
+		checkTestBool(t, true, expected == actions[0].Type)
+	}
+}
+
+func TestPlanTwoWaySyncFolderDelete(t *testing.T) {
+	local, remote, state := testTwoWayState()
+	delete(local, "docs")
+	delete(local, "docs/a.txt")
+	delete(local, "docs/b.txt")
+	actions, err := PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionDelete)
+	checkTestString(t, "docs", actions[0].Path)
+
+	// Folder is kept if it contains remote changes
+	remote["docs/b.txt"].CTag = "b2"
+	actions, err = PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 3, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionCreateLocalDir)
+	checkTestBool(t, true, actions[1].Type == SyncActionDownload)
+	checkTestBool(t, true, actions[2].Type == SyncActionDelete)
+	checkTestString(t, "docs/a.txt", actions[2].Path)
+}
+
+func TestPlanTwoWaySyncEmptySide(t *testing.T) {
+	local, _, state := testTwoWayState()
+	_, err := PlanTwoWaySync("/l", local, map[string]*DriveItem{}, state, SyncOptions{})
+	checkTestBool(t, true, err == ErrSyncSideEmpty)
+
+	_, remote, state := testTwoWayState()
+	_, err = PlanTwoWaySync("/l", map[string]*SyncLocalItem{}, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == ErrSyncSideEmpty)
+
+	// Deleting everything can be explicitly allowed
+	actions, err := PlanTwoWaySync("/l", map[string]*SyncLocalItem{}, remote, state, SyncOptions{AllowEmptySide: true})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(actions))
+	checkTestString(t, "unchanged.md", actions[0].Path)
+	checkTestString(t, "docs", actions[1].Path)
+
+	// Without state there is nothing to delete
+	actions, err = PlanTwoWaySync("/l", local, map[string]*DriveItem{}, &SyncState{Items: make(map[string]*SyncStateItem)}, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 4, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionCreateDir)
+}
+
+func TestPlanTwoWaySyncFirstRun(t *testing.T) {
+	local, remote, _ := testTwoWayState()
+	state := &SyncState{Items: make(map[string]*SyncStateItem)}
+	for p, r := range remote {
+		r.FileSystemInfo.LastModified = local[p].ModTime
+	}
+	remote["docs/b.txt"].FileSystemInfo.LastModified = local["docs/b.txt"].ModTime.Add(time.Hour)
+	actions, err := PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(actions))
+	checkTestBool(t, true, actions[0].Conflict)
+	checkTestString(t, "docs/b.txt", actions[0].Path)
+}
+
+func TestPlanTwoWaySyncCaseInsensitive(t *testing.T) {
+	t1 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	local := map[string]*SyncLocalItem{
+		"Docs":            {Path: "Docs", LocalPath: "/l/Docs", IsDir: true},
+		"Docs/Report.txt": {Path: "Docs/Report.txt", LocalPath: "/l/Docs/Report.txt", SizeBytes: 1, ModTime: t1},
+		"Same.txt":        {Path: "Same.txt", LocalPath: "/l/Same.txt", SizeBytes: 1, ModTime: t1},
+	}
+	remote := map[string]*DriveItem{
+		"docs":            {Name: "docs", Type: DriveItemTypeFolder},
+		"docs/report.txt": {Name: "report.txt", CTag: "r1", SizeBytes: 2, Type: DriveItemTypeFile},
+		"docs/new.txt":    {Name: "new.txt", CTag: "n1", SizeBytes: 1, Type: DriveItemTypeFile},
+		"same.txt":        {Name: "same.txt", CTag: "s1", SizeBytes: 1, Type: DriveItemTypeFile, FileSystemInfo: FileSystemInfo{LastModified: t1}},
+	}
+	state := &SyncState{Items: make(map[string]*SyncStateItem)}
+	actions, err := PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionDownload)
+	checkTestString(t, "docs/new.txt", actions[0].Path)
+	checkTestString(t, filepath.FromSlash("/l/Docs/new.txt"), actions[0].LocalPath)
+	checkTestBool(t, true, actions[1].Type == SyncActionKeepBoth)
+	checkTestBool(t, true, actions[1].Conflict)
+	checkTestString(t, "Docs/Report.txt", actions[1].Path)
+
+	// State recorded with a different case refers to the same item
+	state.RecordUnchanged(local, remote, actions)
+	checkTestBool(t, true, state.Items["Same.txt"] != nil)
+	delete(remote, "same.txt")
+	actions, err = PlanTwoWaySync("/l", local, remote, state, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 3, len(actions))
+	checkTestBool(t, true, actions[2].Type == SyncActionDeleteLocal)
+	checkTestString(t, "Same.txt", actions[2].Path)
+}
+
+func TestExecuteSyncActionDownloadLocalPath(t *testing.T) {
+	server := &testFileServer{Content: []byte("hello"), ETag: "v1"}
+	client := newTestClient(t, server)
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "a:b.txt")
+	action := &SyncAction{Type: SyncActionDownload, Path: "a_b.txt", LocalPath: localPath}
+	checkTestBool(t, true, client.ExecuteSyncAction("/", action) == nil)
+	data, err := os.ReadFile(localPath)
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "hello", string(data))
+	entries, _ := os.ReadDir(dir)
+	checkTestInt(t, 1, len(entries))
+}
+
+func TestCountLocalDeletes(t *testing.T) {
+	_, _, state := testTwoWayState()
+	actions := []*SyncAction{
+		{Type: SyncActionDeleteLocal, Path: "docs"},
+		{Type: SyncActionDelete, Path: "unchanged.md"},
+	}
+	checkTestInt(t, 3, state.CountLocalDeletes(actions))
+}
+
+func TestConflictFileName(t *testing.T) {
+	ts := time.Date(2026, 10, 18, 13, 14, 15, 0, time.UTC)
+	checkTestString(t, "report.conflict-20261018-131415.pdf", ConflictFileName("report.pdf", ts))
+	checkTestString(t, "Makefile.conflict-20261018-131415", ConflictFileName("Makefile", ts))
+}
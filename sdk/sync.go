@@ -0,0 +1,322 @@
+package sdk
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type SyncActionType int
+
+const (
+	SyncActionCreateDir SyncActionType = 1
+	SyncActionUpload    SyncActionType = 2
+	SyncActionDelete    SyncActionType = 3
+	// Only used by two-way syncs
+	SyncActionDownload       SyncActionType = 4
+	SyncActionDeleteLocal    SyncActionType = 5
+	SyncActionCreateLocalDir SyncActionType = 6
+	SyncActionKeepBoth       SyncActionType = 7
+)
+
+func (t SyncActionType) String() string {
+	switch t {
+	case SyncActionCreateDir:
+		return "mkdir"
+	case SyncActionUpload:
+		return "upload"
+	case SyncActionDelete:
+		return "delete"
+	case SyncActionDownload:
+		return "download"
+	case SyncActionDeleteLocal:
+		return "delete-local"
+	case SyncActionCreateLocalDir:
+		return "mkdir-local"
+	case SyncActionKeepBoth:
+		return "keep-both"
+	default:
+		return "unknown"
+	}
+}
+
+// SyncAction is a single step of a sync plan. Path is relative to the synced
+// directories and uses forward slashes.
+type SyncAction struct {
+	Type      SyncActionType
+	Path      string
+	LocalPath string
+	Reason    string
+	Conflict  bool
+	// Replace is set if the item is deleted to make room for an item of
+	// another type
+	Replace bool
+}
+
+type SyncOptions struct {
+	// Delete remote items which don't exist locally (one-way sync only)
+	Delete bool
+	// How to resolve items changed on both sides (two-way sync only)
+	ConflictPolicy ConflictPolicy
+	// Sync even if one side is empty although it was synced before, which
+	// propagates the deletion of all items (two-way sync only)
+	AllowEmptySide bool
+}
+
+// SyncLocalItem describes a local file or directory. Path is the relative
+// path the item gets on the remote side.
+type SyncLocalItem struct {
+	Path      string
+	LocalPath string
+	SizeBytes int64
+	ModTime   time.Time
+	IsDir     bool
+}
+
+// ScanLocalTree returns all files and directories below localDir, keyed by
+// their relative remote path.
+func (client *Client) ScanLocalTree(localDir string) (map[string]*SyncLocalItem, error) {
+	res := make(map[string]*SyncLocalItem)
+	err := filepath.WalkDir(localDir, func(localPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if localPath == localDir {
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			// Skip symlinks, devices etc.
+			return nil
+		}
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		for i := range parts {
+			parts[i] = client.SanitizeFileName(parts[i])
+		}
+		item := &SyncLocalItem{
+			Path:      strings.Join(parts, "/"),
+			LocalPath: localPath,
+			SizeBytes: info.Size(),
+			ModTime:   info.ModTime(),
+			IsDir:     d.IsDir(),
+		}
+		res[item.Path] = item
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ScanRemoteTree returns all items below remoteDir, keyed by their relative
+// path. A non-existing remoteDir results in an empty tree.
+func (client *Client) ScanRemoteTree(remoteDir string) (map[string]*DriveItem, error) {
+	res := make(map[string]*DriveItem)
+	if _, err := client.Info(remoteDir); err != nil {
+		if err == ErrNotFound {
+			return res, nil
+		}
+		return nil, err
+	}
+	if err := client.scanRemoteTree(remoteDir, "", res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (client *Client) scanRemoteTree(remoteDir, prefix string, res map[string]*DriveItem) error {
+	items, err := client.List(path.Join(remoteDir, prefix))
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		rel := path.Join(prefix, item.Name)
+		res[rel] = item
+		if item.Type == DriveItemTypeFolder {
+			if err := client.scanRemoteTree(remoteDir, rel, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// PlanSync compares a local and a remote tree and returns the actions needed
+// to make the remote tree mirror the local one. Directories are created
+// first, then files are uploaded and finally remote extras are deleted.
+// Paths are compared case-insensitively, like OneDrive does.
+func PlanSync(local map[string]*SyncLocalItem, remote map[string]*DriveItem, opts SyncOptions) ([]*SyncAction, error) {
+	var mkdirs, uploads, deletes []*SyncAction
+	localByKey := foldKeys(local)
+	remoteByKey := foldKeys(remote)
+	for _, p := range sortedKeys(local) {
+		l := local[p]
+		r := remoteByKey[strings.ToLower(p)]
+		if r != nil && l.IsDir != (r.Type == DriveItemTypeFolder) {
+			if !opts.Delete {
+				return nil, errors.New("type mismatch between local and remote item: " + p)
+			}
+			deletes = append(deletes, &SyncAction{Type: SyncActionDelete, Path: p, Reason: "type changed", Replace: true})
+			r = nil
+		}
+		if l.IsDir {
+			if r == nil {
+				mkdirs = append(mkdirs, &SyncAction{Type: SyncActionCreateDir, Path: p, LocalPath: l.LocalPath, Reason: "new"})
+			}
+			continue
+		}
+		if r == nil {
+			uploads = append(uploads, &SyncAction{Type: SyncActionUpload, Path: p, LocalPath: l.LocalPath, Reason: "new"})
+			continue
+		}
+		changed, reason, err := syncItemChanged(l, r)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			uploads = append(uploads, &SyncAction{Type: SyncActionUpload, Path: p, LocalPath: l.LocalPath, Reason: reason})
+		}
+	}
+	if opts.Delete {
+		deleted := make(map[string]bool)
+		for _, action := range deletes {
+			deleted[strings.ToLower(action.Path)] = true
+		}
+		for _, p := range sortedKeys(remote) {
+			key := strings.ToLower(p)
+			if deleted[key] || hasDeletedAncestor(key, deleted) {
+				// Deleting a folder deletes its children, too
+				continue
+			}
+			if localByKey[key] != nil {
+				continue
+			}
+			deleted[key] = true
+			deletes = append(deletes, &SyncAction{Type: SyncActionDelete, Path: p, Reason: "not found locally"})
+		}
+	}
+	// Replaced items have to be deleted before they are uploaded again
+	var res []*SyncAction
+	for _, action := range deletes {
+		if action.Replace {
+			res = append(res, action)
+		}
+	}
+	res = append(res, mkdirs...)
+	res = append(res, uploads...)
+	for _, action := range deletes {
+		if !action.Replace {
+			res = append(res, action)
+		}
+	}
+	return res, nil
+}
+
+func hasDeletedAncestor(p string, deleted map[string]bool) bool {
+	for parent := path.Dir(p); parent != "."; parent = path.Dir(parent) {
+		if deleted[parent] {
+			return true
+		}
+	}
+	return false
+}
+
+func syncItemChanged(l *SyncLocalItem, r *DriveItem) (bool, string, error) {
+	if l.SizeBytes != r.SizeBytes {
+		return true, "size changed", nil
+	}
+	// Files with the same size and modification time are considered
+	// unchanged, all others are compared by hash
+	if l.ModTime.Truncate(time.Second).Equal(r.FileSystemInfo.LastModified.Truncate(time.Second)) {
+		return false, "", nil
+	}
+	equal, compared, err := localFileMatchesHashes(l.LocalPath, &r.File.Hashes)
+	if err != nil {
+		return false, "", err
+	}
+	if !compared {
+		return true, "modified", nil
+	}
+	if !equal {
+		return true, "content changed", nil
+	}
+	return false, "", nil
+}
+
+// RemoteFileMatches checks if the remote file has the same size and content
+// as the local file. If the remote item provides no hash, the files are
+// considered different.
+func (client *Client) RemoteFileMatches(localFilePath, remotePath string) (bool, error) {
+	info, err := os.Stat(localFilePath)
+	if err != nil {
+		return false, err
+	}
+	item, err := client.Info(remotePath)
+	if err != nil {
+		return false, err
+	}
+	if item.Type != DriveItemTypeFile || item.SizeBytes != info.Size() {
+		return false, nil
+	}
+	equal, compared, err := localFileMatchesHashes(localFilePath, &item.File.Hashes)
+	if err != nil {
+		return false, err
+	}
+	return equal && compared, nil
+}
+
+// ExecuteSyncAction performs a single action of a sync plan.
+func (client *Client) ExecuteSyncAction(remoteDir string, action *SyncAction) error {
+	remotePath := path.Join("/", remoteDir, action.Path)
+	switch action.Type {
+	case SyncActionCreateDir:
+		return client.CreateDir(remotePath)
+	case SyncActionUpload:
+		return client.Upload(action.LocalPath, path.Dir(remotePath))
+	case SyncActionDelete:
+		return client.Delete(remotePath)
+	case SyncActionDownload:
+		localDir := filepath.Dir(action.LocalPath)
+		if err := os.MkdirAll(localDir, 0755); err != nil {
+			return err
+		}
+		return client.Download(remotePath, action.LocalPath)
+	case SyncActionDeleteLocal:
+		return os.RemoveAll(action.LocalPath)
+	case SyncActionCreateLocalDir:
+		return os.MkdirAll(action.LocalPath, 0755)
+	default:
+		return errors.New("unknown sync action")
+	}
+}
+
+// foldKeys returns a copy of m keyed by the lower case paths.
+func foldKeys[T any](m map[string]T) map[string]T {
+	res := make(map[string]T, len(m))
+	for k, v := range m {
+		res[strings.ToLower(k)] = v
+	}
+	return res
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanSync(t *testing.T) {
+	now := time.Now()
+	local := map[string]*SyncLocalItem{
+		"new.txt":       {Path: "new.txt", SizeBytes: 10, ModTime: now},
+		"same.txt":      {Path: "same.txt", SizeBytes: 10, ModTime: now},
+		"grown.txt":     {Path: "grown.txt", SizeBytes: 20, ModTime: now.Add(-time.Hour)},
+		"sub":           {Path: "sub", IsDir: true},
+		"sub/child.txt": {Path: "sub/child.txt", SizeBytes: 1, ModTime: now},
+	}
+	remote := map[string]*DriveItem{
+		"same.txt":       {Name: "same.txt", SizeBytes: 10, Type: DriveItemTypeFile, FileSystemInfo: FileSystemInfo{LastModified: now}},
+		"grown.txt":      {Name: "grown.txt", SizeBytes: 10, Type: DriveItemTypeFile, FileSystemInfo: FileSystemInfo{LastModified: now}},
+		"extra.txt":      {Name: "extra.txt", SizeBytes: 10, Type: DriveItemTypeFile},
+		"old":            {Name: "old", Type: DriveItemTypeFolder},
+		"old/nested.txt": {Name: "nested.txt", SizeBytes: 10, Type: DriveItemTypeFile},
+	}
+
+	actions, err := PlanSync(local, remote, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 4, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionCreateDir)
+	checkTestString(t, "sub", actions[0].Path)
+	checkTestString(t, "grown.txt", actions[1].Path)
+	checkTestString(t, "new.txt", actions[2].Path)
+	checkTestString(t, "sub/child.txt", actions[3].Path)
+
+	actions, err = PlanSync(local, remote, SyncOptions{Delete: true})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 6, len(actions))
+	checkTestBool(t, true, actions[4].Type == SyncActionDelete)
+	checkTestString(t, "extra.txt", actions[4].Path)
+	checkTestString(t, "old", actions[5].Path)
+}
+
+func TestPlanSyncTypeMismatch(t *testing.T) {
+	local := map[string]*SyncLocalItem{
+		"x": {Path: "x", SizeBytes: 1},
+	}
+	remote := map[string]*DriveItem{
+		"x":     {Name: "x", Type: DriveItemTypeFolder},
+		"x/a.t": {Name: "a.t", Type: DriveItemTypeFile},
+	}
+	_, err := PlanSync(local, remote, SyncOptions{})
+	checkTestBool(t, false, err == nil)
+
+	actions, err := PlanSync(local, remote, SyncOptions{Delete: true})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(actions))
+	checkTestBool(t, true, actions[0].Type == SyncActionDelete)
+	checkTestBool(t, true, actions[0].Replace)
+	checkTestBool(t, true, actions[1].Type == SyncActionUpload)
+}
+
+func TestPlanSyncCaseInsensitive(t *testing.T) {
+	now := time.Now()
+	local := map[string]*SyncLocalItem{
+		"Docs":         {Path: "Docs", IsDir: true},
+		"Docs/Foo.txt": {Path: "Docs/Foo.txt", SizeBytes: 10, ModTime: now},
+	}
+	remote := map[string]*DriveItem{
+		"docs":         {Name: "docs", Type: DriveItemTypeFolder},
+		"docs/foo.txt": {Name: "foo.txt", SizeBytes: 10, Type: DriveItemTypeFile, FileSystemInfo: FileSystemInfo{LastModified: now}},
+	}
+
+	actions, err := PlanSync(local, remote, SyncOptions{Delete: true})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 0, len(actions))
+}
+
+func TestPlanSyncHashCompare(t *testing.T) {
+	dir := t.TempDir()
+	localPath := filepath.Join(dir, "a.txt")
+	os.WriteFile(localPath, []byte("hello"), 0600)
+	local := map[string]*SyncLocalItem{
+		"a.txt": {Path: "a.txt", LocalPath: localPath, SizeBytes: 5, ModTime: time.Now()},
+	}
+	remote := map[string]*DriveItem{
+		"a.txt": {
+			Name:      "a.txt",
+			SizeBytes: 5,
+			Type:      DriveItemTypeFile,
+			File: FileItem{Hashes: FileHashes{
+				SHA1: "AAF4C61DDCC5E8A2DABEDE0F3B482CD9AEA9434D",
+			}},
+		},
+	}
+	actions, err := PlanSync(local, remote, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 0, len(actions))
+
+	remote["a.txt"].File.Hashes.SHA1 = "0000000000000000000000000000000000000000"
+	actions, err = PlanSync(local, remote, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(actions))
+	checkTestString(t, "content changed", actions[0].Reason)
+
+	// Local files older than the remote ones are compared, too
+	remote["a.txt"].FileSystemInfo.LastModified = time.Now().Add(time.Hour)
+	actions, err = PlanSync(local, remote, SyncOptions{})
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 1, len(actions))
+	checkTestString(t, "content changed", actions[0].Reason)
+}
+
+func TestScanLocalTree(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "sub"), 0700)
+	os.WriteFile(filepath.Join(dir, "sub", "a:b.txt"), []byte("x"), 0600)
+	c := &Client{}
+	items, err := c.ScanLocalTree(dir)
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(items))
+	checkTestBool(t, true, items["sub"].IsDir)
+	checkTestInt(t, 1, int(items["sub/a_b.txt"].SizeBytes))
+}
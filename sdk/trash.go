@@ -0,0 +1,51 @@
+package sdk
+
+import (
+	"errors"
+	"net/http"
+	"path"
+)
+
+type RestoreRequest struct {
+	ParentReference *ItemReference `json:"parentReference,omitempty"`
+	Name            string         `json:"name,omitempty"`
+}
+
+// Restore restores a deleted item from the recycle bin. If parentPath is
+// set, the item is restored into this folder instead of its original one.
+// If name is set, the item is renamed. Restoring is only supported for
+// personal OneDrive accounts.
+func (client *Client) Restore(id, parentPath, name string) (*DriveItem, error) {
+	if id == "" {
+		return nil, errors.New("please specify the ID of the deleted item")
+	}
+	req := &RestoreRequest{
+		Name: name,
+	}
+	if parentPath != "" {
+		parent, err := client.Info(path.Clean("/" + parentPath))
+		if err != nil {
+			return nil, err
+		}
+		req.ParentReference = &ItemReference{ID: parent.ID}
+	}
+	url := GraphURL + "me/drive/items/" + id + "/restore"
+	status, data, err := client.httpPostJSON(url, req)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if status == http.StatusConflict {
+		return nil, &ConflictError{Path: path.Join("/", parentPath, name)}
+	}
+	if !IsHTTPStatusOK(status) {
+		return nil, client.handleResponseError(status, data)
+	}
+	var item DriveItem
+	if err := UnmarshalJSON(&item, data); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
@@ -0,0 +1,36 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	var restored RestoreRequest
+	var restorePath string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "POST" {
+			restorePath = r.URL.Path
+			json.Unmarshal(data, &restored)
+			w.Write([]byte(`{"id":"item-id","name":"restored.txt"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"folder-id","name":"docs","folder":{"childCount":0}}`))
+	}))
+
+	item, err := client.Restore("item-id", "/docs", "restored.txt")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "restored.txt", item.Name)
+	checkTestString(t, "/me/drive/items/item-id/restore", restorePath)
+	checkTestString(t, "folder-id", restored.ParentReference.ID)
+	checkTestString(t, "restored.txt", restored.Name)
+
+	restored = RestoreRequest{}
+	_, err = client.Restore("item-id", "", "")
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, restored.ParentReference == nil)
+}
@@ -22,6 +22,54 @@ type FolderItem struct {
 	ChildCount int `json:"childCount"`
 }
 
+type ItemReference struct {
+	DriveID   string `json:"driveId,omitempty"`
+	DriveType string `json:"driveType,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+type Identity struct {
+	ID          string `json:"id,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+type IdentitySet struct {
+	User        *Identity `json:"user,omitempty"`
+	Application *Identity `json:"application,omitempty"`
+	Device      *Identity `json:"device,omitempty"`
+}
+
+// Name returns the display name of the user, application or device, in this
+// order of preference.
+func (s *IdentitySet) Name() string {
+	for _, identity := range []*Identity{s.User, s.Application, s.Device} {
+		if identity == nil {
+			continue
+		}
+		if identity.DisplayName != "" {
+			return identity.DisplayName
+		}
+		if identity.Email != "" {
+			return identity.Email
+		}
+	}
+	return ""
+}
+
+type DeletedItem struct {
+	State string `json:"state"`
+}
+
+type PackageItem struct {
+	Type string `json:"type"`
+}
+
+type SpecialFolderItem struct {
+	Name string `json:"name"`
+}
+
 type DriveItemType int
 
 const (
@@ -30,12 +78,19 @@ const (
 )
 
 type DriveItem struct {
-	Name           string         `json:"name"`
-	SizeBytes      int64          `json:"size"`
-	File           FileItem       `json:"file"`
-	Folder         FolderItem     `json:"folder"`
-	FileSystemInfo FileSystemInfo `json:"fileSystemInfo"`
-	Type           DriveItemType
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	ETag            string             `json:"eTag"`
+	CTag            string             `json:"cTag"`
+	SizeBytes       int64              `json:"size"`
+	File            FileItem           `json:"file"`
+	Folder          FolderItem         `json:"folder"`
+	FileSystemInfo  FileSystemInfo     `json:"fileSystemInfo"`
+	ParentReference ItemReference      `json:"parentReference"`
+	Deleted         *DeletedItem       `json:"deleted,omitempty"`
+	Package         *PackageItem       `json:"package,omitempty"`
+	SpecialFolder   *SpecialFolderItem `json:"specialFolder,omitempty"`
+	Type            DriveItemType
 }
 
 type ErrorResponse struct {
@@ -3,6 +3,7 @@ package sdk
 import (
 	"errors"
 	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,15 +24,52 @@ type UploadSessionResponse struct {
 
 type EmptyStruct struct{}
 
+type UploadSessionItem struct {
+	ConflictBehavior ConflictBehavior `json:"@microsoft.graph.conflictBehavior,omitempty"`
+	FileSystemInfo   *FileSystemInfo  `json:"fileSystemInfo,omitempty"`
+}
+
+type UploadSessionRequest struct {
+	Item UploadSessionItem `json:"item"`
+}
+
+type FileSystemInfoUpdate struct {
+	FileSystemInfo *FileSystemInfo `json:"fileSystemInfo"`
+}
+
+// Upload uploads the local file to the target folder. Existing remote files
+// are handled according to the client's ConflictBehavior, which defaults to
+// replacing them.
 func (client *Client) Upload(localFilePath, targetFolder string) error {
+	return client.UploadAs(localFilePath, targetFolder, filepath.Base(localFilePath))
+}
+
+// UploadAs uploads the local file to the target folder, storing it as
+// remoteName instead of the local file's name.
+func (client *Client) UploadAs(localFilePath, targetFolder, remoteName string) error {
+	_, err := client.uploadAs(localFilePath, targetFolder, remoteName)
+	return err
+}
+
+// UploadItem uploads the local file like Upload and returns the uploaded
+// item. Its name differs from the local one if the file has been renamed due
+// to a conflict.
+func (client *Client) UploadItem(localFilePath, targetFolder string) (*DriveItem, error) {
+	return client.uploadAs(localFilePath, targetFolder, filepath.Base(localFilePath))
+}
+
+func (client *Client) uploadAs(localFilePath, targetFolder, remoteName string) (*DriveItem, error) {
 	if len(targetFolder) > 0 && targetFolder[0] == '.' {
-		return errors.New("invalid target path (should start with /)")
+		return nil, errors.New("invalid target path (should start with /)")
 	}
-	fileName := filepath.Base(localFilePath)
+	localFileName := filepath.Base(localFilePath)
+	if localFileName == "" || localFileName == "." || localFileName == ".." {
+		return nil, errors.New("please specify a file, not a directory")
+	}
+	fileName := client.SanitizeFileName(remoteName)
 	if fileName == "" || fileName == "." || fileName == ".." {
-		return errors.New("please specify a file, not a directory")
+		return nil, errors.New("invalid remote file name")
 	}
-	fileName = client.sanitizeFileName(fileName)
 	targetFolder = strings.TrimPrefix(strings.TrimSuffix(targetFolder, "/"), "/")
 	if !strings.HasSuffix(targetFolder, "/") {
 		targetFolder += "/"
@@ -41,30 +79,93 @@ func (client *Client) Upload(localFilePath, targetFolder string) error {
 	}
 	fileStat, err := os.Stat(localFilePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	mimeType := mime.TypeByExtension(filepath.Ext(strings.TrimSpace(localFilePath)))
+	mimeType := mime.TypeByExtension(filepath.Ext(fileName))
 	if mimeType == "" {
 		mimeType = "application/octet-stream"
 	}
+	var hasher *transferHasher
+	if client.VerifyTransfers {
+		hasher = newTransferHasher()
+	}
+	timestamps := localFileSystemInfo(fileStat)
 	client.signalTransferStart(fileStat)
+	var item *DriveItem
 	if fileStat.Size() < int64(UploadSessionFileSizeLimit) {
 		// Use simple upload
-		res := client.uploadSimple(fileName, mimeType, targetFolder, localFilePath)
+		item, err = client.uploadSimple(fileName, mimeType, targetFolder, localFilePath, hasher)
+		client.signalTransferFinish()
+		if err == nil {
+			// Simple uploads can't carry metadata, so it's set afterwards
+			err = client.updateFileSystemInfo(item.ID, timestamps)
+		}
+	} else {
+		// Use upload session
+		var session *UploadSessionResponse
+		session, err = client.startUploadSession(fileName, targetFolder, timestamps)
+		if err != nil {
+			return nil, err
+		}
+		item, err = client.uploadToSession(session.UploadURL, targetFolder+fileName, mimeType, localFilePath, fileStat.Size(), hasher)
 		client.signalTransferFinish()
-		return res
 	}
-	// Use upload session
-	session, err := client.startUploadSession(fileName, targetFolder)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	res := client.uploadToSession(session.UploadURL, mimeType, localFilePath, fileStat.Size())
-	client.signalTransferFinish()
-	return res
+	if item.Name == "" {
+		item.Name = fileName
+	}
+	if hasher == nil {
+		return item, nil
+	}
+	if err := client.verifyUpload(targetFolder+item.Name, item, hasher); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (client *Client) verifyUpload(remotePath string, item *DriveItem, hasher *transferHasher) error {
+	if algorithm, _ := item.File.Hashes.Strongest(); algorithm == "" {
+		// Hashes might not be part of the upload response
+		var err error
+		item, err = client.Info(remotePath)
+		if err != nil {
+			return err
+		}
+	}
+	err := hasher.Verify(remotePath, &item.File.Hashes)
+	if _, ok := err.(*IntegrityError); ok && client.DeleteCorruptFiles {
+		if delErr := client.Delete(remotePath); delErr != nil {
+			return errors.New(err.Error() + " (could not delete remote file: " + delErr.Error() + ")")
+		}
+	}
+	return err
 }
 
-func (client *Client) sanitizeFileName(fileName string) string {
+// UploadIfChanged uploads the local file unless a remote file with the same
+// size and hash already exists in the target folder. The first result is
+// false if the upload was skipped.
+func (client *Client) UploadIfChanged(localFilePath, targetFolder string) (bool, error) {
+	return client.UploadAsIfChanged(localFilePath, targetFolder, filepath.Base(localFilePath))
+}
+
+// UploadAsIfChanged is like UploadIfChanged, but stores the file as
+// remoteName.
+func (client *Client) UploadAsIfChanged(localFilePath, targetFolder, remoteName string) (bool, error) {
+	remotePath := strings.TrimSuffix(targetFolder, "/") + "/" + client.SanitizeFileName(remoteName)
+	unchanged, err := client.RemoteFileMatches(localFilePath, remotePath)
+	if err != nil && err != ErrNotFound {
+		return false, err
+	}
+	if unchanged {
+		return false, nil
+	}
+	return true, client.UploadAs(localFilePath, targetFolder, remoteName)
+}
+
+// SanitizeFileName replaces characters not allowed in OneDrive file names.
+func (client *Client) SanitizeFileName(fileName string) string {
 	res := strings.TrimSpace(fileName)
 	for i := 0; i < len(InvalidFilenameCharacters); i++ {
 		c := string(InvalidFilenameCharacters[i])
@@ -73,46 +174,69 @@ func (client *Client) sanitizeFileName(fileName string) string {
 	return res
 }
 
-func (client *Client) uploadToSession(uploadUrl, mimeType, localFilePath string, fileSize int64) error {
+// uploadToSession uploads the file in parts. A name conflict is only reported
+// in response to the last part.
+func (client *Client) uploadToSession(uploadUrl, remotePath, mimeType, localFilePath string, fileSize int64, hasher *transferHasher) (*DriveItem, error) {
 	if (client.UploadSessionRangeSize <= 0) || (client.UploadSessionRangeSize%320 != 0) {
-		return errors.New("upload session range size must be a multiple of 320")
+		return nil, errors.New("upload session range size must be a multiple of 320")
 	}
 	rangeSizeBytes := client.UploadSessionRangeSize * 1024
 	data := make([]byte, rangeSizeBytes)
 	f, err := os.Open(localFilePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer f.Close()
 	var offset int64 = 0
 	n := 0
+	var resp []byte
 	for offset < fileSize {
 		n, _ = f.ReadAt(data, offset)
 		if n < rangeSizeBytes {
 			data = append([]byte(nil), data[:n]...)
 		}
+		if hasher != nil {
+			hasher.Write(data[:n])
+		}
 		progress := func(b int64) {
 			client.signalTransferProgress(b + offset)
 		}
-		status, _, err := client.httpSendFilePart("PUT", uploadUrl, mimeType, offset, int64(n), fileSize, data, progress)
+		var status int
+		status, resp, err = client.httpSendFilePart("PUT", uploadUrl, mimeType, offset, int64(n), fileSize, data, progress)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if status == http.StatusConflict {
+			return nil, &ConflictError{Path: remotePath}
 		}
 		if !IsHTTPStatusOK(status) {
-			return client.handleResponseError(status, data)
+			return nil, client.handleResponseError(status, resp)
 		}
 		offset += int64(n)
 	}
-	return nil
+	// The response to the last part contains the uploaded item
+	var item DriveItem
+	if err := UnmarshalJSON(&item, resp); err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
-func (client *Client) startUploadSession(fileName, targetFolder string) (*UploadSessionResponse, error) {
+func (client *Client) startUploadSession(fileName, targetFolder string, timestamps *FileSystemInfo) (*UploadSessionResponse, error) {
 	url := GraphURL + "me" + client.Config.Root + ":" + targetFolder + fileName + ":/createUploadSession"
-	payload := &EmptyStruct{}
+	payload := &UploadSessionRequest{
+		Item: UploadSessionItem{
+			ConflictBehavior: client.ConflictBehavior,
+			FileSystemInfo:   timestamps,
+		},
+	}
 	status, data, err := client.httpPostJSON(url, payload)
 	if err != nil {
 		return nil, err
 	}
+	if status == http.StatusConflict {
+		return nil, &ConflictError{Path: targetFolder + fileName}
+	}
 	if !IsHTTPStatusOK(status) {
 		return nil, client.handleResponseError(status, data)
 	}
@@ -123,16 +247,48 @@ func (client *Client) startUploadSession(fileName, targetFolder string) (*Upload
 	return &uploadSession, nil
 }
 
-func (client *Client) uploadSimple(fileName, mimeType, targetFolder, localFilePath string) error {
+func (client *Client) uploadSimple(fileName, mimeType, targetFolder, localFilePath string, hasher *transferHasher) (*DriveItem, error) {
 	data, err := os.ReadFile(localFilePath)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if hasher != nil {
+		hasher.Write(data)
 	}
 	url := GraphURL + "me" + client.Config.Root + ":" + targetFolder + fileName + ":/content"
+	if client.ConflictBehavior != "" {
+		params := make(HTTPRequestParams)
+		params["@microsoft.graph.conflictBehavior"] = string(client.ConflictBehavior)
+		url = client.buildURI(url, params)
+	}
 	progress := func(b int64) {
 		client.signalTransferProgress(b)
 	}
-	status, _, err := client.httpSendFile("PUT", url, mimeType, data, progress)
+	status, resp, err := client.httpSendFile("PUT", url, mimeType, data, progress)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusConflict {
+		return nil, &ConflictError{Path: targetFolder + fileName}
+	}
+	if !IsHTTPStatusOK(status) {
+		return nil, client.handleResponseError(status, resp)
+	}
+	var item DriveItem
+	if err := UnmarshalJSON(&item, resp); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// updateFileSystemInfo sets the creation and modification time of a remote
+// item.
+func (client *Client) updateFileSystemInfo(id string, timestamps *FileSystemInfo) error {
+	url := GraphURL + "me/drive/items/" + id
+	payload := &FileSystemInfoUpdate{
+		FileSystemInfo: timestamps,
+	}
+	status, data, err := client.httpSendJSON("PATCH", url, payload)
 	if err != nil {
 		return err
 	}
@@ -141,3 +297,12 @@ func (client *Client) uploadSimple(fileName, mimeType, targetFolder, localFilePa
 	}
 	return nil
 }
+
+// localFileSystemInfo returns the timestamps of a local file as they are
+// stored in OneDrive.
+func localFileSystemInfo(info os.FileInfo) *FileSystemInfo {
+	return &FileSystemInfo{
+		Created:      fileCreationTime(info).UTC(),
+		LastModified: info.ModTime().UTC(),
+	}
+}
@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadSetsFileSystemInfo(t *testing.T) {
+	var patched FileSystemInfoUpdate
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.Method == "PATCH" && strings.HasSuffix(r.URL.Path, "/items/item-id") {
+			json.Unmarshal(data, &patched)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"item-id","name":"file.txt"}`))
+	}))
+	localFile := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(localFile, []byte("hello"), 0600)
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	os.Chtimes(localFile, modTime, modTime)
+
+	err := client.Upload(localFile, "/")
+	checkTestBool(t, true, err == nil)
+	checkTestBool(t, true, patched.FileSystemInfo != nil)
+	checkTestBool(t, true, patched.FileSystemInfo.LastModified.Equal(modTime))
+}
+
+func TestUploadConflictFail(t *testing.T) {
+	var query string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		query = r.URL.Query().Get("@microsoft.graph.conflictBehavior")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error":{"code":"nameAlreadyExists","message":"exists"}}`))
+	}))
+	client.ConflictBehavior = ConflictBehaviorFail
+	localFile := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(localFile, []byte("hello"), 0600)
+
+	err := client.Upload(localFile, "/test")
+	conflictErr, ok := err.(*ConflictError)
+	checkTestBool(t, true, ok)
+	checkTestString(t, "/test/file.txt", conflictErr.Path)
+	checkTestString(t, "fail", query)
+}
+
+func TestUploadAs(t *testing.T) {
+	var paths []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"item-id","name":"backup_2026.tar.gz"}`))
+	}))
+	localFile := filepath.Join(t.TempDir(), "backup.tar.gz")
+	os.WriteFile(localFile, []byte("hello"), 0600)
+
+	err := client.UploadAs(localFile, "/backup", "backup:2026.tar.gz")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "/me/drive/root:/backup/backup_2026.tar.gz:/content", paths[0])
+	err = client.UploadAs(localFile, "/backup", "..")
+	checkTestBool(t, true, err != nil)
+}
+
+func TestUploadItemRenamed(t *testing.T) {
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"item-id","name":"scan 1.pdf"}`))
+	}))
+	client.ConflictBehavior = ConflictBehaviorRename
+	localFile := filepath.Join(t.TempDir(), "scan.pdf")
+	os.WriteFile(localFile, []byte("hello"), 0600)
+
+	item, err := client.UploadItem(localFile, "/scans")
+	checkTestBool(t, true, err == nil)
+	checkTestString(t, "scan 1.pdf", item.Name)
+}
+
+func TestUploadSessionConflictFail(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, ":/createUploadSession"):
+			w.Write([]byte(`{"uploadUrl":"` + GraphURL + `session"}`))
+		case r.URL.Path == "/session" && strings.HasSuffix(r.Header.Get("Content-Range"), "-716799/716800"):
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":{"code":"nameAlreadyExists","message":"exists"}}`))
+		case r.URL.Path == "/session":
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"nextExpectedRanges":["0-"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	client.ConflictBehavior = ConflictBehaviorFail
+	client.UploadSessionRangeSize = 320
+	oldLimit := UploadSessionFileSizeLimit
+	UploadSessionFileSizeLimit = 100
+	defer func() { UploadSessionFileSizeLimit = oldLimit }()
+	localFile := filepath.Join(t.TempDir(), "big.bin")
+	os.WriteFile(localFile, make([]byte, 700*1024), 0600)
+
+	err := client.Upload(localFile, "/test")
+	conflictErr, ok := err.(*ConflictError)
+	checkTestBool(t, true, ok)
+	checkTestString(t, "/test/big.bin", conflictErr.Path)
+	checkTestInt(t, 4, len(requests))
+	checkTestString(t, "PUT /session", requests[3])
+}
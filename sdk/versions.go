@@ -0,0 +1,129 @@
+package sdk
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type DriveItemVersion struct {
+	ID             string      `json:"id"`
+	LastModified   time.Time   `json:"lastModifiedDateTime"`
+	SizeBytes      int64       `json:"size"`
+	LastModifiedBy IdentitySet `json:"lastModifiedBy"`
+}
+
+type VersionsResponse struct {
+	Versions []*DriveItemVersion `json:"value"`
+	NextLink string              `json:"@odata.nextLink"`
+}
+
+// Versions returns the versions of the remote file, the current version
+// first.
+func (client *Client) Versions(path string) ([]*DriveItemVersion, error) {
+	url, err := client.versionsURL(path)
+	if err != nil {
+		return nil, err
+	}
+	return getPaged[*DriveItemVersion](client, url, nil)
+}
+
+// OpenVersionContent returns a reader for the content of a version of the
+// remote file. The caller has to close the reader.
+func (client *Client) OpenVersionContent(path, versionID string) (io.ReadCloser, error) {
+	url, err := client.versionsURL(path)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", url+"/"+versionID+"/content", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+client.Config.AccessToken)
+	httpClient := &http.Client{}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, client.handleResponseError(resp.StatusCode, data)
+	}
+	return resp.Body, nil
+}
+
+// DownloadVersion downloads a version of the remote file to target, which
+// may be an existing directory or the path of the local file. Like Download,
+// the data is written to a ".part" file first, and an existing target file
+// is handled according to the client's ExistingFiles policy.
+func (client *Client) DownloadVersion(path, versionID, target string) error {
+	if stat, err := os.Stat(target); err == nil && stat.IsDir() {
+		target = filepath.Join(target, filepath.Base(path))
+	}
+	if client.ExistingFiles == ExistingFileNoClobber {
+		if _, err := os.Stat(target); err == nil {
+			return ErrFileExists
+		}
+	}
+	reader, err := client.OpenVersionContent(path, versionID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	partFile := target + ".part"
+	out, err := os.Create(partFile)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, reader)
+	if err == nil {
+		err = out.Sync()
+	}
+	out.Close()
+	if err == nil {
+		err = client.replaceFile(partFile, target)
+	}
+	if err != nil {
+		os.Remove(partFile)
+		return err
+	}
+	return nil
+}
+
+// RestoreVersion makes a previous version the current version of the remote
+// file. The current content is kept as another version.
+func (client *Client) RestoreVersion(path, versionID string) error {
+	url, err := client.versionsURL(path)
+	if err != nil {
+		return err
+	}
+	status, data, err := client.httpPostJSON(url+"/"+versionID+"/restoreVersion", &EmptyStruct{})
+	if err != nil {
+		return err
+	}
+	if status == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if !IsHTTPStatusOK(status) {
+		return client.handleResponseError(status, data)
+	}
+	return nil
+}
+
+func (client *Client) versionsURL(path string) (string, error) {
+	url, err := client.itemURL(path)
+	if err != nil {
+		return "", err
+	}
+	return url + "versions", nil
+}
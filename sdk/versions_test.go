@@ -0,0 +1,64 @@
+package sdk
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersions(t *testing.T) {
+	var requests []string
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/me/drive/root:/config.json:/versions":
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Query().Get("page") == "" {
+				w.Write([]byte(`{"value":[
+					{"id":"2.0","size":20,"lastModifiedDateTime":"2026-10-18T10:00:00Z","lastModifiedBy":{"user":{"displayName":"Alice"}}}
+				],"@odata.nextLink":"` + GraphURL + `me/drive/root:/config.json:/versions?page=2"}`))
+				return
+			}
+			w.Write([]byte(`{"value":[
+				{"id":"1.0","size":10,"lastModifiedDateTime":"2026-10-17T10:00:00Z","lastModifiedBy":{"application":{"displayName":"Backup"}}}
+			]}`))
+		case "/me/drive/root:/config.json:/versions/1.0/content":
+			w.Write([]byte("old content"))
+		case "/me/drive/root:/config.json:/versions/1.0/restoreVersion":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	versions, err := client.Versions("/config.json")
+	checkTestBool(t, true, err == nil)
+	checkTestInt(t, 2, len(versions))
+	checkTestString(t, "2.0", versions[0].ID)
+	checkTestInt(t, 10, int(versions[1].SizeBytes))
+	checkTestString(t, "Alice", versions[0].LastModifiedBy.Name())
+	checkTestString(t, "Backup", versions[1].LastModifiedBy.Name())
+
+	reader, err := client.OpenVersionContent("/config.json", "1.0")
+	checkTestBool(t, true, err == nil)
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	checkTestString(t, "old content", string(data))
+
+	dir := t.TempDir()
+	checkTestBool(t, true, client.DownloadVersion("/config.json", "1.0", dir) == nil)
+	data, _ = os.ReadFile(filepath.Join(dir, "config.json"))
+	checkTestString(t, "old content", string(data))
+	client.ExistingFiles = ExistingFileNoClobber
+	checkTestBool(t, true, client.DownloadVersion("/config.json", "1.0", dir) == ErrFileExists)
+	_, err = os.Stat(filepath.Join(dir, "config.json.part"))
+	checkTestBool(t, true, os.IsNotExist(err))
+
+	checkTestBool(t, true, client.RestoreVersion("/config.json", "1.0") == nil)
+	checkTestString(t, "POST /me/drive/root:/config.json:/versions/1.0/restoreVersion", requests[len(requests)-1])
+
+	_, err = client.Versions("/missing.json")
+	checkTestBool(t, true, err == ErrNotFound)
+}